@@ -0,0 +1,32 @@
+package gouml
+
+import (
+	"github.com/go-kit/kit/log"
+	"github.com/kazukousen/gouml/internal/gouml/plantuml"
+	"github.com/kazukousen/gouml/model"
+)
+
+// Checker is a Parser checking the imports of the packages against a
+// model.Contract. It writes the package diagram with the violations in red.
+type Checker interface {
+	Parser
+	// Violations returns the imports breaking the contract, after Build.
+	Violations() []*model.Violation
+}
+
+// NewChecker ...
+func NewChecker(logger log.Logger, opts Options, contract *model.Contract) Checker {
+	return &checker{
+		parser:   NewParser(logger, plantuml.NewDepsRenderer(opts.External, contract), opts).(*parser),
+		contract: contract,
+	}
+}
+
+type checker struct {
+	*parser
+	contract *model.Contract
+}
+
+func (c *checker) Violations() []*model.Violation {
+	return c.contract.Violations(c.graph)
+}
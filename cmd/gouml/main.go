@@ -2,6 +2,7 @@ package main
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
@@ -10,11 +11,13 @@ import (
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
 	"github.com/kazukousen/gouml"
+	"github.com/kazukousen/gouml/model"
 	"github.com/urfave/cli"
 )
 
 func main() {
-	logger := log.NewLogfmtLogger(os.Stdout)
+	// stdout is left to the diagrams written with `-o -`
+	logger := log.NewLogfmtLogger(os.Stderr)
 	logger = log.With(logger, "ts", log.DefaultTimestamp)
 
 	flags := []cli.Flag{
@@ -30,6 +33,56 @@ func main() {
 			Name:  "verbose",
 			Usage: "debugging",
 		},
+		&cli.BoolFlag{
+			Name:  "strict",
+			Usage: "Fail on type errors instead of reporting them as warnings",
+		},
+		&cli.StringFlag{
+			Name:  "diagnostics",
+			Usage: "Print the unresolved imports, invalid types and skipped files on stderr: text or json",
+		},
+		&cli.BoolFlag{
+			Name:  "hide-promoted",
+			Usage: "Hide the methods promoted from embedded fields and interfaces",
+		},
+		&cli.BoolFlag{
+			Name:  "collapse-aliases",
+			Usage: "Draw the relations to a type alias to the aliased type, without a node for the alias",
+		},
+		&cli.BoolFlag{
+			Name:  "constant-notes",
+			Usage: "List the constants of a type in a note instead of drawing the type as an enum",
+		},
+		&cli.BoolFlag{
+			Name:  "show-tags",
+			Usage: "Show the struct tags of the fields",
+		},
+		&cli.BoolFlag{
+			Name:  "docs",
+			Usage: "Show the doc comments of the types as notes and the comments of the members",
+		},
+		&cli.IntFlag{
+			Name:  "doc-limit",
+			Value: 80,
+			Usage: "Cut the comments of the members longer than this many characters, 0 for no limit",
+		},
+		&cli.StringFlag{
+			Name:  "preset",
+			Value: "ddd",
+			Usage: "Default kinds of the types: ddd (entity or value object) or plain (class)",
+		},
+		&cli.StringFlag{
+			Name:  "rules",
+			Usage: "JSON file of the classification rules giving stereotypes to the types",
+		},
+		&cli.BoolFlag{
+			Name:  "roles",
+			Usage: "Recognise the repositories, services and factories and draw them with their own stereotypes",
+		},
+		&cli.BoolFlag{
+			Name:  "layers",
+			Usage: "Group the repositories, services and factories into layers, implies --roles",
+		},
 	}
 	app := cli.NewApp()
 	app.Version = "0.2"
@@ -40,29 +93,194 @@ func main() {
 			Aliases: []string{"i"},
 			Usage:   "Create *.puml",
 			Action: func(c *cli.Context) error {
-				buf := &bytes.Buffer{}
-				buf.WriteString("@startuml\n")
-				if err := generate(logger, buf, c.StringSlice("ignore"), c.StringSlice("file"), c.Bool("verbose")); err != nil {
-					return err
+				f, ok := formats[c.String("format")]
+				if !ok {
+					return fmt.Errorf("unknown format: %s", c.String("format"))
 				}
-				buf.WriteString("@enduml\n")
 
+				opts, err := options(c)
+				if err != nil {
+					return err
+				}
+				opts.Focus = c.String("focus")
+				opts.Depth = c.Int("depth")
+				opts.Direction = model.Direction(c.String("direction"))
+				if opts.Focus != "" {
+					// before loading the packages, which may take long
+					if err := model.CheckFocus(opts.Focus, opts.Depth, opts.Direction); err != nil {
+						return err
+					}
+				}
+				if dir := c.String("out-dir"); dir != "" {
+					if c.String("format") != "plantuml" {
+						return fmt.Errorf("--out-dir writes plantuml only")
+					}
+					return writeDir(logger, dir, gouml.NewDirParser(logger, opts, c.String("link-ext")), c)
+				}
 				out := c.String("out")
-				out, err := filepath.Abs(out)
+				if !c.IsSet("out") {
+					out = "file" + f.ext
+				}
+				return output(out, f.header, f.footer, func(w io.Writer) error {
+					return generate(logger, w, f.parser(logger, opts), c)
+				})
+			},
+			Flags: append(flags, []cli.Flag{
+				&cli.StringFlag{
+					Name:  "out, o",
+					Value: "file.puml",
+					Usage: "File Name you want to parsed, - for stdout",
+				},
+				&cli.StringFlag{
+					Name:  "format",
+					Value: "plantuml",
+					Usage: "Output format: plantuml, mermaid or dot",
+				},
+				&cli.StringFlag{
+					Name:  "out-dir",
+					Usage: "Directory to write one diagram per package and an index into, instead of one file",
+				},
+				&cli.StringFlag{
+					Name:  "link-ext",
+					Value: ".svg",
+					Usage: "Extension of the files the diagrams written with --out-dir link to",
+				},
+				&cli.StringFlag{
+					Name:  "focus",
+					Usage: "Draw only the type named like pkg.Type and its neighbors",
+				},
+				&cli.IntFlag{
+					Name:  "depth",
+					Value: 1,
+					Usage: "Number of relations followed from the focused type",
+				},
+				&cli.StringFlag{
+					Name:  "direction",
+					Value: "both",
+					Usage: "Direction the relations are followed in from the focused type: out, in or both",
+				},
+			}...),
+		},
+		{
+			Name:  "export",
+			Usage: "Export the analysed model in a machine-readable format",
+			Action: func(c *cli.Context) error {
+				parser, ok := exportFormats[c.String("format")]
+				if !ok {
+					return fmt.Errorf("unknown format: %s", c.String("format"))
+				}
+
+				opts, err := options(c)
 				if err != nil {
 					return err
 				}
-				if err := writeFile(out, buf); err != nil {
+				return output(c.String("out"), "", "", func(w io.Writer) error {
+					return generate(logger, w, parser(logger, opts), c)
+				})
+			},
+			Flags: append(flags, []cli.Flag{
+				&cli.StringFlag{
+					Name:  "out, o",
+					Value: "file.json",
+					Usage: "File Name you want to export",
+				},
+				&cli.StringFlag{
+					Name:  "format",
+					Value: "json",
+					Usage: "Export format: json",
+				},
+			}...),
+		},
+		{
+			Name:  "erd",
+			Usage: "Create an entity-relationship diagram of the structs with db or gorm tags",
+			Action: func(c *cli.Context) error {
+				opts, err := options(c)
+				if err != nil {
+					return err
+				}
+				return output(c.String("out"), "@startuml\n", "@enduml\n", func(w io.Writer) error {
+					return generate(logger, w, gouml.ERDParser(logger, opts), c)
+				})
+			},
+			Flags: append(flags, []cli.Flag{
+				&cli.StringFlag{
+					Name:  "out, o",
+					Value: "erd.puml",
+					Usage: "File Name you want to parsed, - for stdout",
+				},
+			}...),
+		},
+		{
+			Name:  "packages",
+			Usage: "Create a diagram of the packages and the imports between them",
+			Action: func(c *cli.Context) error {
+				opts, err := options(c)
+				if err != nil {
 					return err
 				}
-				fmt.Printf("output to file: %s\n", out)
+				opts.External = c.Bool("external")
+				return output(c.String("out"), "@startuml\n", "@enduml\n", func(w io.Writer) error {
+					return generate(logger, w, gouml.DepsParser(logger, opts), c)
+				})
+			},
+			Flags: append(flags, []cli.Flag{
+				&cli.StringFlag{
+					Name:  "out, o",
+					Value: "packages.puml",
+					Usage: "File Name you want to parsed, - for stdout",
+				},
+				&cli.BoolFlag{
+					Name:  "external",
+					Usage: "Draw the imported modules, the standard library as std",
+				},
+			}...),
+		},
+		{
+			Name:  "check",
+			Usage: "Check the imports between the packages against the layers of a contract",
+			Action: func(c *cli.Context) error {
+				contract, err := readContract(c.String("contract"))
+				if err != nil {
+					return err
+				}
+				opts, err := options(c)
+				if err != nil {
+					return err
+				}
+				opts.External = c.Bool("external")
+				checker := gouml.NewChecker(logger, opts, contract)
+				err = output(c.String("out"), "@startuml\n", "@enduml\n", func(w io.Writer) error {
+					return generate(logger, w, checker, c)
+				})
+				if err != nil {
+					return err
+				}
+
+				// on stderr, not to mix with the diagram written to stdout
+				vs := checker.Violations()
+				for _, v := range vs {
+					fmt.Fprintln(os.Stderr, v)
+				}
+				if len(vs) > 0 {
+					return cli.NewExitError(fmt.Sprintf("%d imports break the contract", len(vs)), 1)
+				}
 				return nil
 			},
 			Flags: append(flags, []cli.Flag{
+				&cli.StringFlag{
+					Name:  "contract",
+					Value: "contract.json",
+					Usage: "JSON file of the layers and the directions of their dependencies",
+				},
 				&cli.StringFlag{
 					Name:  "out, o",
-					Value: "file.puml",
-					Usage: "File Name you want to parsed",
+					Value: "check.puml",
+					Usage: "File Name you want to parsed, - for stdout",
+				},
+				&cli.BoolFlag{
+					Name:  "external",
+					Usage: "Draw the imported modules, the standard library as std",
 				},
 			}...),
 		},
@@ -72,11 +290,15 @@ func main() {
 			Usage:   "encode base64",
 			Action: func(c *cli.Context) error {
 				buf := &bytes.Buffer{}
-				if err := generate(logger, buf, c.StringSlice("ignore"), c.StringSlice("file"), c.Bool("verbose")); err != nil {
+				opts, err := options(c)
+				if err != nil {
+					return err
+				}
+				if err := generate(logger, buf, gouml.PlantUMLParser(logger, opts), c); err != nil {
 					return err
 				}
 
-				fmt.Printf(gouml.Compress(buf.String()))
+				fmt.Print(gouml.Compress(buf.String()))
 				return nil
 			},
 			Flags: append(flags, []cli.Flag{}...),
@@ -85,16 +307,118 @@ func main() {
 
 	if err := app.Run(os.Args); err != nil {
 		level.Error(logger).Log("msg", "failed to run", "error", err)
+		os.Exit(1)
+	}
+}
+
+// format describes how a diagram is wrapped and saved for each output backend.
+type format struct {
+	parser func(logger log.Logger, opts gouml.Options) gouml.Parser
+	header string
+	footer string
+	ext    string
+}
+
+var formats = map[string]format{
+	"plantuml": {
+		parser: gouml.PlantUMLParser,
+		header: "@startuml\n",
+		footer: "@enduml\n",
+		ext:    ".puml",
+	},
+	"mermaid": {
+		parser: gouml.MermaidParser,
+		header: "classDiagram\n",
+		ext:    ".mmd",
+	},
+	"dot": {
+		parser: gouml.DOTParser,
+		header: "digraph gouml {",
+		footer: "}\n",
+		ext:    ".dot",
+	},
+}
+
+var exportFormats = map[string]func(logger log.Logger, opts gouml.Options) gouml.Parser{
+	"json": gouml.JSONParser,
+}
+
+func options(c *cli.Context) (gouml.Options, error) {
+	opts := gouml.Options{}
+	if file := c.String("rules"); file != "" {
+		f, err := os.Open(file)
+		if err != nil {
+			return opts, err
+		}
+		defer f.Close()
+		if err := json.NewDecoder(f).Decode(&opts.Classification); err != nil {
+			return opts, fmt.Errorf("read rules %s: %w", file, err)
+		}
+	}
+	if c.IsSet("preset") {
+		opts.Classification.Preset = c.String("preset")
 	}
+	if err := opts.Classification.Check(); err != nil {
+		return opts, err
+	}
+	opts.HidePromoted = c.Bool("hide-promoted")
+	opts.CollapseAliases = c.Bool("collapse-aliases")
+	opts.ConstantNotes = c.Bool("constant-notes")
+	opts.Tags = c.Bool("show-tags")
+	opts.Docs = c.Bool("docs")
+	opts.DocLimit = c.Int("doc-limit")
+	opts.Roles = c.Bool("roles")
+	opts.Layers = c.Bool("layers")
+	return opts, nil
 }
 
-func generate(logger log.Logger, buf *bytes.Buffer, ignores []string, targets []string, verbose bool) error {
-	gen := gouml.NewGenerator(logger, gouml.PlantUMLParser(logger), verbose)
-	if len(ignores) > 0 {
+func writeDir(logger log.Logger, dir string, parser gouml.DirParser, c *cli.Context) error {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	err = writeFile(filepath.Join(dir, "index.puml"), "@startuml\n", "@enduml\n", func(w io.Writer) error {
+		return generate(logger, w, parser, c)
+	})
+	if err != nil {
+		return err
+	}
+	files, err := parser.WriteDir(dir)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("output to directory: %s (%d packages)\n", dir, len(files))
+	return nil
+}
+
+func readContract(file string) (*model.Contract, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	contract := &model.Contract{}
+	if err := json.NewDecoder(f).Decode(contract); err != nil {
+		return nil, fmt.Errorf("read contract %s: %w", file, err)
+	}
+	if err := contract.Check(); err != nil {
+		return nil, fmt.Errorf("contract %s: %w", file, err)
+	}
+	return contract, nil
+}
+
+func generate(logger log.Logger, w io.Writer, parser gouml.Parser, c *cli.Context) error {
+	gen := gouml.NewGenerator(logger, parser, c.Bool("verbose"))
+	gen.SetStrict(c.Bool("strict"))
+	if ignores := c.StringSlice("ignore"); len(ignores) > 0 {
 		if err := gen.UpdateIgnore(ignores); err != nil {
 			return err
 		}
 	}
+	targets := c.StringSlice("file")
 	if len(targets) == 0 {
 		targets = []string{"./"}
 	}
@@ -102,20 +426,82 @@ func generate(logger log.Logger, buf *bytes.Buffer, ignores []string, targets []
 		return err
 	}
 
-	gen.WriteTo(buf)
+	_, err := gen.WriteTo(w)
+	if format := c.String("diagnostics"); format != "" {
+		// on stderr, not to mix with the diagram written to stdout
+		if err := gouml.WriteDiagnostics(os.Stderr, gen.Diagnostics(), format); err != nil {
+			return err
+		}
+	}
+	return err
+}
+
+// output streams the diagram written by write, framed by header and footer,
+// to stdout if file is "-", or else to the file.
+func output(file, header, footer string, write func(w io.Writer) error) error {
+	if file == "-" {
+		w := &framed{w: os.Stdout, header: header}
+		if err := write(w); err != nil {
+			return err
+		}
+		return w.end(footer)
+	}
+	file, err := filepath.Abs(file)
+	if err != nil {
+		return err
+	}
+	if err := writeFile(file, header, footer, write); err != nil {
+		return err
+	}
+	fmt.Printf("output to file: %s\n", file)
 	return nil
 }
 
-func writeFile(file string, buf io.Reader) (e error) {
+// writeFile writes the diagram written by write, framed by header and
+// footer, to the file, which is removed on failure.
+func writeFile(file, header, footer string, write func(w io.Writer) error) (e error) {
 	f, err := os.Create(file)
 	if err != nil {
 		return err
 	}
 	defer func() {
-		if err := f.Close(); err != nil {
+		if err := f.Close(); err != nil && e == nil {
 			e = err
 		}
+		if e != nil {
+			os.Remove(file)
+		}
 	}()
-	io.Copy(f, buf)
-	return
+	w := &framed{w: f, header: header}
+	if err := write(w); err != nil {
+		return err
+	}
+	return w.end(footer)
+}
+
+// framed writes the header before the first write, so that nothing is
+// written when the diagram fails before being written.
+type framed struct {
+	w       io.Writer
+	header  string
+	started bool
+}
+
+func (f *framed) Write(p []byte) (int, error) {
+	if !f.started {
+		f.started = true
+		if _, err := io.WriteString(f.w, f.header); err != nil {
+			return 0, err
+		}
+	}
+	return f.w.Write(p)
+}
+
+// end writes the footer, after the header if nothing was written.
+func (f *framed) end(footer string) error {
+	if _, err := f.Write(nil); err != nil {
+		return err
+	}
+	_, err := io.WriteString(f.w, footer)
+	return err
 }
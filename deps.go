@@ -0,0 +1,12 @@
+package gouml
+
+import (
+	"github.com/go-kit/kit/log"
+	"github.com/kazukousen/gouml/internal/gouml/plantuml"
+)
+
+// DepsParser writes the packages and the imports between them as a PlantUML
+// package diagram.
+func DepsParser(logger log.Logger, opts Options) Parser {
+	return NewParser(logger, plantuml.NewDepsRenderer(opts.External, nil), opts)
+}
@@ -0,0 +1,200 @@
+package gouml
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"go/types"
+	"io"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// DiagnosticKind classifies a Diagnostic.
+type DiagnosticKind string
+
+// Kinds of a Diagnostic.
+const (
+	// DiagnosticUnresolvedImport is an import the loader could not find. The
+	// types of the imported package are invalid types.
+	DiagnosticUnresolvedImport DiagnosticKind = "unresolved_import"
+	// DiagnosticUnresolvedPackage is a target the loader found no Go files
+	// for, e.g. a misspelled import path or a pattern matching no packages.
+	DiagnosticUnresolvedPackage DiagnosticKind = "unresolved_package"
+	// DiagnosticTypeError is any other error of the type checker.
+	DiagnosticTypeError DiagnosticKind = "type_error"
+	// DiagnosticInvalidType is a declaration drawn with an invalid type
+	// because of an error.
+	DiagnosticInvalidType DiagnosticKind = "invalid_type"
+	// DiagnosticSkippedFile is a file left out of the diagram.
+	DiagnosticSkippedFile DiagnosticKind = "skipped_file"
+)
+
+// Diagnostic tells why a part of a diagram may be missing.
+type Diagnostic struct {
+	Kind DiagnosticKind `json:"kind"`
+	// Pos is the position like file:line:col, or the file, when known.
+	Pos string `json:"pos,omitempty"`
+	// Package is the import path of the package concerned, when known.
+	Package string `json:"package,omitempty"`
+	Message string `json:"message"`
+}
+
+// IsError reports whether d is an error of the loaded code, which fails a
+// strict Generator.
+func (d Diagnostic) IsError() bool {
+	return d.Kind == DiagnosticUnresolvedPackage || d.Kind == DiagnosticUnresolvedImport || d.Kind == DiagnosticTypeError
+}
+
+// String returns the position and the message, like the compiler does.
+func (d Diagnostic) String() string {
+	if d.Pos == "" {
+		return d.Message
+	}
+	return d.Pos + ": " + d.Message
+}
+
+// WriteDiagnostics writes ds to w in the format text, one per line followed
+// by the kind, or json.
+func WriteDiagnostics(w io.Writer, ds []Diagnostic, format string) error {
+	switch format {
+	case "text":
+		for _, d := range ds {
+			if _, err := fmt.Fprintf(w, "%s (%s)\n", d, d.Kind); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if ds == nil {
+			ds = []Diagnostic{}
+		}
+		return enc.Encode(ds)
+	}
+	return fmt.Errorf("unknown format: %s", format)
+}
+
+// errorKind classifies an error message of the loader or the type checker.
+func errorKind(msg string) DiagnosticKind {
+	if strings.Contains(msg, "could not import") {
+		return DiagnosticUnresolvedImport
+	}
+	return DiagnosticTypeError
+}
+
+// packageDiagnostics returns the errors of pkg. The errors of the go command
+// are left out when the type checker reports them as well.
+func packageDiagnostics(pkg *packages.Package) []Diagnostic {
+	checked := false
+	for _, err := range pkg.Errors {
+		checked = checked || err.Kind == packages.TypeError
+	}
+	ds := []Diagnostic{}
+	for _, err := range pkg.Errors {
+		if checked && err.Kind == packages.ListError {
+			continue
+		}
+		pos := err.Pos
+		if pos == "-" {
+			pos = ""
+		}
+		ds = append(ds, Diagnostic{Kind: errorKind(err.Msg), Pos: pos, Package: pkg.PkgPath, Message: err.Msg})
+	}
+	return ds
+}
+
+// typeDiagnostic converts an error reported by the type checker.
+func typeDiagnostic(path string, err error) Diagnostic {
+	if terr, ok := err.(types.Error); ok {
+		return Diagnostic{Kind: errorKind(terr.Msg), Pos: terr.Fset.Position(terr.Pos).String(), Package: path, Message: terr.Msg}
+	}
+	return Diagnostic{Kind: errorKind(err.Error()), Package: path, Message: err.Error()}
+}
+
+// invalidTypes returns the declarations of pkg drawn with an invalid type:
+// the fields, the methods, the functions and the variables.
+func invalidTypes(fset *token.FileSet, pkg *types.Package) []Diagnostic {
+	ds := []Diagnostic{}
+	report := func(obj types.Object, what string) {
+		ds = append(ds, Diagnostic{
+			Kind:    DiagnosticInvalidType,
+			Pos:     fset.Position(obj.Pos()).String(),
+			Package: pkg.Path(),
+			Message: what + " has an invalid type",
+		})
+	}
+	scope := pkg.Scope()
+	for _, name := range scope.Names() {
+		switch obj := scope.Lookup(name).(type) {
+		case *types.TypeName:
+			if obj.IsAlias() {
+				if invalid(obj.Type()) {
+					report(obj, "alias "+name)
+				}
+				continue
+			}
+			if st, ok := obj.Type().Underlying().(*types.Struct); ok {
+				for i := 0; i < st.NumFields(); i++ {
+					if f := st.Field(i); invalid(f.Type()) {
+						report(f, "field "+name+"."+f.Name())
+					}
+				}
+			} else if invalid(obj.Type().Underlying()) {
+				report(obj, "type "+name)
+			}
+			if named, ok := obj.Type().(*types.Named); ok {
+				for i := 0; i < named.NumMethods(); i++ {
+					if m := named.Method(i); invalid(m.Type()) {
+						report(m, "method "+name+"."+m.Name())
+					}
+				}
+			}
+		case *types.Func:
+			if invalid(obj.Type()) {
+				report(obj, "function "+name)
+			}
+		case *types.Var:
+			if invalid(obj.Type()) {
+				report(obj, "variable "+name)
+			}
+		}
+	}
+	return ds
+}
+
+// invalid reports whether typ is or is made of an invalid type, without
+// looking into the named types.
+func invalid(typ types.Type) bool {
+	switch t := typ.(type) {
+	case *types.Basic:
+		return t.Kind() == types.Invalid
+	case *types.Pointer:
+		return invalid(t.Elem())
+	case *types.Slice:
+		return invalid(t.Elem())
+	case *types.Array:
+		return invalid(t.Elem())
+	case *types.Chan:
+		return invalid(t.Elem())
+	case *types.Map:
+		return invalid(t.Key()) || invalid(t.Elem())
+	case *types.Signature:
+		for _, tuple := range []*types.Tuple{t.Params(), t.Results()} {
+			for i := 0; i < tuple.Len(); i++ {
+				if invalid(tuple.At(i).Type()) {
+					return true
+				}
+			}
+		}
+	case *types.Interface:
+		for i := 0; i < t.NumExplicitMethods(); i++ {
+			if invalid(t.ExplicitMethod(i).Type()) {
+				return true
+			}
+		}
+	}
+	return false
+}
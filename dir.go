@@ -0,0 +1,89 @@
+package gouml
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/kazukousen/gouml/internal/gouml/naming"
+	"github.com/kazukousen/gouml/internal/gouml/plantuml"
+	"github.com/kazukousen/gouml/model"
+)
+
+// DirParser is a Parser writing the PlantUML index diagram of the packages,
+// which also writes one PlantUML diagram per package into a directory.
+type DirParser interface {
+	Parser
+	// WriteDir writes the diagram of each package into dir after Build, and
+	// returns the paths of the files written.
+	WriteDir(dir string) ([]string, error)
+}
+
+// NewDirParser returns a DirParser linking the diagrams to each other by the
+// names of their files with the extension ext, e.g. .svg for the rendered
+// diagrams.
+func NewDirParser(logger log.Logger, opts Options, ext string) DirParser {
+	link := func(pkg *model.Package) string {
+		return PackageFile(pkg) + ext
+	}
+	return &dirParser{
+		parser: NewParser(logger, plantuml.NewIndexRenderer(link), opts).(*parser),
+		link:   link,
+	}
+}
+
+// PackageFile returns the name of the file of the diagram of pkg, without
+// an extension.
+func PackageFile(pkg *model.Package) string {
+	return naming.EscapeID(pkg.Path)
+}
+
+type dirParser struct {
+	*parser
+	link func(pkg *model.Package) string
+}
+
+func (p *dirParser) WriteDir(dir string) ([]string, error) {
+	start := time.Now()
+	defer func() {
+		elapsed := time.Since(start)
+		level.Debug(p.logger).Log("msg", "write to directory", "ms", elapsed.Truncate(time.Millisecond))
+	}()
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	renderer := plantuml.NewRenderer()
+	files := []string{}
+	for _, part := range model.Split(p.graph, p.link) {
+		file := filepath.Join(dir, PackageFile(part.Package)+".puml")
+		if err := writePart(file, renderer, part.Graph); err != nil {
+			return files, err
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+func writePart(file string, renderer Renderer, g *model.Graph) (e error) {
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := f.Close(); err != nil && e == nil {
+			e = err
+		}
+	}()
+	if _, err := io.WriteString(f, "@startuml\n"); err != nil {
+		return err
+	}
+	if _, err := renderer.Render(f, g); err != nil {
+		return err
+	}
+	_, err = io.WriteString(f, "@enduml\n")
+	return err
+}
@@ -0,0 +1,11 @@
+package gouml
+
+import (
+	"github.com/go-kit/kit/log"
+	"github.com/kazukousen/gouml/internal/gouml/dot"
+)
+
+// DOTParser ...
+func DOTParser(logger log.Logger, opts Options) Parser {
+	return NewParser(logger, dot.NewRenderer(), opts)
+}
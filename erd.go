@@ -0,0 +1,12 @@
+package gouml
+
+import (
+	"github.com/go-kit/kit/log"
+	"github.com/kazukousen/gouml/internal/gouml/plantuml"
+)
+
+// ERDParser writes the structs with `db` or `gorm` tags as a PlantUML
+// entity-relationship diagram.
+func ERDParser(logger log.Logger, opts Options) Parser {
+	return NewParser(logger, plantuml.NewERDRenderer(), opts)
+}
@@ -1,38 +1,56 @@
 package gouml
 
 import (
-	"bytes"
+	"errors"
 	"fmt"
 	"go/ast"
-	"go/importer"
-	"go/parser"
 	"go/token"
 	"go/types"
+	"io"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
+	"github.com/kazukousen/gouml/model"
+	"golang.org/x/tools/go/packages"
 )
 
 // Generator ...
 type Generator interface {
 	UpdateIgnore(files []string) error
 	Read(files []string) error
-	WriteTo(buf *bytes.Buffer) error
+	// SetStrict makes WriteTo fail on type errors and on targets without Go
+	// files. Otherwise they are logged as warnings, and the diagram may be
+	// incomplete.
+	SetStrict(strict bool)
+	// Diagnostics returns what was found wrong or left out while reading and
+	// writing, in order.
+	Diagnostics() []Diagnostic
+	// WriteTo loads the packages read and writes their diagram to w.
+	WriteTo(w io.Writer) (int64, error)
 }
 
 type generator struct {
-	logger      log.Logger
-	parser      Parser
-	targets     []string
+	logger log.Logger
+	parser Parser
+	// queries holds the patterns and the files to load by the directory the
+	// go command runs in, "" for the working directory.
+	queries     map[string]*query
+	files       map[string]struct{}
 	ignoreFiles map[string]struct{}
 	fset        *token.FileSet
-	astPkgs     map[string]*ast.Package
-	pkgs        []*types.Package
+	pkgs        []*model.Source
 	isDebug     bool
+	strict      bool
+	// skipped holds the diagnostics of Read, and diags the ones of the last
+	// WriteTo.
+	skipped []Diagnostic
+	diags   []Diagnostic
 }
 
 // NewGenerator ...
@@ -40,34 +58,67 @@ func NewGenerator(logger log.Logger, parser Parser, isDebug bool) Generator {
 	return &generator{
 		logger:      log.With(logger, "component", "generator"),
 		parser:      parser,
-		targets:     []string{},
+		queries:     map[string]*query{},
+		files:       map[string]struct{}{},
 		ignoreFiles: map[string]struct{}{},
 		fset:        token.NewFileSet(),
-		astPkgs:     map[string]*ast.Package{},
-		pkgs:        []*types.Package{},
+		pkgs:        []*model.Source{},
 		isDebug:     isDebug,
 	}
 }
 
-func (g generator) WriteTo(buf *bytes.Buffer) error {
-	if err := g.ast(); err != nil {
-		return err
+// query is what is loaded in one directory.
+type query struct {
+	patterns []string
+	files    []string
+}
+
+// query returns the query of the directory, "" for the working directory.
+func (g *generator) query(dir string) *query {
+	q, ok := g.queries[dir]
+	if !ok {
+		q = &query{}
+		g.queries[dir] = q
 	}
-	if err := g.check(); err != nil {
-		return err
+	return q
+}
+
+func (g *generator) SetStrict(strict bool) {
+	g.strict = strict
+}
+
+func (g *generator) Diagnostics() []Diagnostic {
+	return append(append([]Diagnostic{}, g.skipped...), g.diags...)
+}
+
+func (g *generator) WriteTo(w io.Writer) (int64, error) {
+	if err := g.load(); err != nil {
+		return 0, err
 	}
-	g.parser.Build(g.pkgs)
-	g.parser.WriteTo(buf)
-	return nil
+	errs := []error{}
+	for _, d := range g.diags {
+		if d.IsError() {
+			errs = append(errs, errors.New(d.String()))
+		}
+	}
+	if len(errs) > 0 {
+		if g.strict {
+			return 0, fmt.Errorf("%d errors:\n%w", len(errs), errors.Join(errs...))
+		}
+		for _, err := range errs {
+			level.Warn(g.logger).Log("msg", "load error", "error", err)
+		}
+		level.Warn(g.logger).Log("msg", "the diagram may be incomplete", "errors", len(errs))
+	}
+	if err := g.parser.Build(g.pkgs); err != nil {
+		return 0, err
+	}
+	return g.parser.WriteTo(w)
 }
 
+// Read accepts files, directories and package patterns such as ./... or
+// github.com/org/svc/internal/... . Patterns are resolved like `go build` does.
 func (g *generator) Read(files []string) error {
-	start := time.Now()
-	defer func() {
-		elapsed := time.Since(start)
-		level.Debug(g.logger).Log("msg", "read .go files", "ms", elapsed.Truncate(time.Millisecond))
-	}()
-
 	for _, f := range files {
 		if err := g.read(f); err != nil {
 			return err
@@ -79,38 +130,62 @@ func (g *generator) Read(files []string) error {
 func (g *generator) read(f string) error {
 	fInfo, err := os.Stat(f)
 	if err != nil {
-		return err
-	}
-
-	if fInfo.IsDir() {
-		if err := filepath.Walk(f, g.visit); err != nil {
+		if !isPattern(f) {
 			return err
 		}
+		// not a path on disk, so hand it over to the go command as a package pattern
+		q := g.query("")
+		q.patterns = append(q.patterns, f)
 		return nil
 	}
-
-	if err := g.visit(f, nil, nil); err != nil {
+	path, err := filepath.Abs(f)
+	if err != nil {
 		return err
 	}
-	return nil
-}
 
-func (g *generator) visit(path string, f os.FileInfo, err error) error {
+	if fInfo.IsDir() {
+		// a directory means the packages in it and all of its subdirectories,
+		// loaded from its module wherever gouml runs
+		q := g.query(moduleRoot(path))
+		q.patterns = append(q.patterns, filepath.Join(path, "..."))
+		return nil
+	}
+
 	if ext := filepath.Ext(path); ext != ".go" {
+		g.skipped = append(g.skipped, Diagnostic{Kind: DiagnosticSkippedFile, Pos: path, Message: "not a Go file"})
 		return nil
 	}
 	if strings.HasSuffix(path, "_test.go") {
+		g.skipped = append(g.skipped, Diagnostic{Kind: DiagnosticSkippedFile, Pos: path, Message: "test file"})
 		return nil
 	}
-	path, err = filepath.Abs(path)
-	if err != nil {
-		return err
+	g.files[path] = struct{}{}
+	q := g.query(moduleRoot(filepath.Dir(path)))
+	q.files = append(q.files, "file="+path)
+	return nil
+}
+
+// moduleRoot returns the directory of the go.mod above dir, or dir itself
+// outside of a module.
+func moduleRoot(dir string) string {
+	for d := dir; ; d = filepath.Dir(d) {
+		if _, err := os.Stat(filepath.Join(d, "go.mod")); err == nil {
+			return d
+		}
+		if filepath.Dir(d) == d {
+			return dir
+		}
 	}
-	if _, ok := g.ignoreFiles[path]; ok {
-		return nil
+}
+
+// isPattern reports whether f, not found on disk, is meant as a package
+// pattern: an import path, or a path with a ... wildcard. Other paths are
+// typos.
+func isPattern(f string) bool {
+	if strings.Contains(f, "...") {
+		return true
 	}
-	g.targets = append(g.targets, path)
-	return nil
+	return !filepath.IsAbs(f) && !strings.HasPrefix(f, ".")
 }
 
 func (g *generator) UpdateIgnore(files []string) error {
@@ -150,57 +225,217 @@ func (g *generator) doUpdateIgnore(path string, f os.FileInfo, err error) error
 	return nil
 }
 
-func (g generator) ast() error {
+func (g *generator) load() error {
 	start := time.Now()
 	defer func() {
 		elapsed := time.Since(start)
-		level.Debug(g.logger).Log("msg", "parsed to AST", "ms", elapsed.Truncate(time.Millisecond))
+		level.Debug(g.logger).Log("msg", "loaded packages", "ms", elapsed.Truncate(time.Millisecond))
 	}()
 
-	for _, path := range g.targets {
+	// every call loads the packages again, from scratch
+	g.pkgs = []*model.Source{}
+	g.diags = []Diagnostic{}
+	dirs := make([]string, 0, len(g.queries))
+	for dir := range g.queries {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+
+	roots := []*packages.Package{}
+	matched := map[string]bool{}
+	for _, dir := range dirs {
+		q := g.queries[dir]
+		conf := &packages.Config{
+			Mode: packages.NeedName | packages.NeedFiles | packages.NeedImports | packages.NeedTypes | packages.NeedSyntax | packages.NeedTypesInfo | packages.NeedModule,
+			Fset: g.fset,
+			Dir:  dir,
+		}
+		if len(q.patterns) > 0 {
+			pkgs, err := packages.Load(conf, q.patterns...)
+			if err != nil {
+				return fmt.Errorf("load packages: %w", err)
+			}
+			for _, pkg := range pkgs {
+				matched[pkg.ID] = true
+			}
+			// the go command only warns about a pattern matching no packages
+			for _, pattern := range q.patterns {
+				if strings.Contains(pattern, "...") && !matchesAny(pattern, pkgs) {
+					g.diags = append(g.diags, Diagnostic{Kind: DiagnosticUnresolvedPackage, Message: fmt.Sprintf("%s: matched no packages", pattern)})
+				}
+			}
+			roots = append(roots, pkgs...)
+		}
+		if len(q.files) > 0 {
+			pkgs, err := packages.Load(conf, q.files...)
+			if err != nil {
+				return fmt.Errorf("load packages: %w", err)
+			}
+			roots = append(roots, pkgs...)
+		}
+	}
+
+	// packages are keyed by import path, so that two directories sharing a
+	// package name (e.g. `config`) are never merged into one.
+	seen := map[string]struct{}{}
+	for _, pkg := range roots {
+		if _, ok := seen[pkg.PkgPath]; ok {
+			continue
+		}
+		seen[pkg.PkgPath] = struct{}{}
+
 		if g.isDebug {
-			fmt.Printf("parsing AST: %s\n", path)
+			level.Debug(g.logger).Log("msg", "loaded package", "path", pkg.PkgPath)
 		}
-		astFile, err := parser.ParseFile(g.fset, path, nil, parser.ParseComments)
-		if err != nil {
-			return fmt.Errorf("ParseFile panic: %w", err)
-		}
-		name := astFile.Name.Name
-		pkg, ok := g.astPkgs[name]
-		if !ok {
-			pkg = &ast.Package{
-				Name:  name,
-				Files: make(map[string]*ast.File),
+		if len(pkg.Syntax) == 0 {
+			// e.g. an import path not found, or a directory without Go files
+			for _, d := range packageDiagnostics(pkg) {
+				d.Kind = DiagnosticUnresolvedPackage
+				g.diags = append(g.diags, d)
+			}
+			continue
+		}
+
+		for _, f := range pkg.IgnoredFiles {
+			if matched[pkg.ID] {
+				g.diags = append(g.diags, Diagnostic{Kind: DiagnosticSkippedFile, Pos: f, Package: pkg.PkgPath, Message: "excluded by build constraints"})
+			}
+		}
+		files := make([]*ast.File, 0, len(pkg.Syntax))
+		for _, f := range pkg.Syntax {
+			path := g.fset.Position(f.Pos()).Filename
+			if _, ok := g.ignoreFiles[path]; ok {
+				g.diags = append(g.diags, Diagnostic{Kind: DiagnosticSkippedFile, Pos: path, Package: pkg.PkgPath, Message: "ignored"})
+				continue
 			}
+			// packages only reached through a single file keep just the given files.
+			if _, ok := g.files[path]; !ok && !matched[pkg.ID] {
+				continue
+			}
+			files = append(files, f)
+		}
+
+		switch len(files) {
+		case 0:
+			continue
+		case len(pkg.Syntax):
+			g.diags = append(g.diags, packageDiagnostics(pkg)...)
+			g.pkgs = append(g.pkgs, &model.Source{Types: pkg.Types, Files: files, Info: pkg.TypesInfo, Modules: modules(pkg)})
+		default:
+			g.pkgs = append(g.pkgs, g.check(pkg, files))
 		}
-		pkg.Files[path] = astFile
-		g.astPkgs[name] = pkg
+	}
+	sort.Slice(g.pkgs, func(i, j int) bool {
+		return g.pkgs[i].Types.Path() < g.pkgs[j].Types.Path()
+	})
+	for _, src := range g.pkgs {
+		g.diags = append(g.diags, invalidTypes(g.fset, src.Types)...)
 	}
 	return nil
 }
 
-func (g *generator) check() error {
-	start := time.Now()
-	defer func() {
-		elapsed := time.Since(start)
-		level.Debug(g.logger).Log("msg", "checked type", "ms", elapsed.Truncate(time.Millisecond))
-	}()
+// check type-checks a subset of the files of pkg, reusing the dependencies
+// already resolved by the go command.
+func (g *generator) check(pkg *packages.Package, files []*ast.File) *model.Source {
+	imports := map[string]*types.Package{}
+	for _, imp := range pkg.Types.Imports() {
+		imports[imp.Path()] = imp
+	}
 
 	conf := types.Config{
-		Importer: importer.For("source", nil),
-		Error: func(err error) {
-			if g.isDebug {
-				fmt.Printf("error: %+v\n", err)
+		Importer: importerFunc(func(path string) (*types.Package, error) {
+			if path == "unsafe" {
+				return types.Unsafe, nil
+			}
+			if imp, ok := pkg.Imports[path]; ok {
+				path = imp.ID
+			}
+			if imp, ok := imports[path]; ok {
+				return imp, nil
 			}
+			return nil, fmt.Errorf("could not import %s", path)
+		}),
+		// the errors of the other files of pkg do not matter
+		Error: func(err error) {
+			g.diags = append(g.diags, typeDiagnostic(pkg.PkgPath, err))
 		},
 	}
-	for _, astPkg := range g.astPkgs {
-		files := make([]*ast.File, 0, len(astPkg.Files))
-		for _, f := range astPkg.Files {
-			files = append(files, f)
+	info := &types.Info{
+		Types: map[ast.Expr]types.TypeAndValue{},
+		Defs:  map[*ast.Ident]types.Object{},
+		Uses:  map[*ast.Ident]types.Object{},
+	}
+	// the errors are all reported to conf.Error, and the package is checked
+	// as much as possible anyway
+	checked, _ := conf.Check(pkg.PkgPath, g.fset, files, info)
+	return &model.Source{Types: checked, Files: files, Info: info, Modules: modules(pkg)}
+}
+
+// matchesAny reports whether the pattern matches one of pkgs, like the go
+// command matches an import path, or a directory for a local pattern.
+func matchesAny(pattern string, pkgs []*packages.Package) bool {
+	for _, pkg := range pkgs {
+		if pkg.ID == pattern {
+			// the go command reports why it could not list it
+			return true
 		}
-		pkg, _ := conf.Check(astPkg.Name, g.fset, files, nil)
-		g.pkgs = append(g.pkgs, pkg)
 	}
-	return nil
+	local := filepath.IsAbs(pattern) || strings.HasPrefix(pattern, ".")
+	if local {
+		abs, err := filepath.Abs(pattern)
+		if err != nil {
+			return true
+		}
+		// the directories are compared without symbolic links, up to the
+		// first wildcard
+		abs = filepath.ToSlash(abs)
+		i := strings.LastIndex(abs[:strings.Index(abs, "...")], "/")
+		pattern = filepath.ToSlash(realpath(abs[:i])) + abs[i:]
+	}
+	expr := strings.ReplaceAll(regexp.QuoteMeta(pattern), `\.\.\.`, `.*`)
+	if prefix, ok := strings.CutSuffix(expr, `/.*`); ok {
+		// a/... matches a itself too
+		expr = prefix + `(/.*)?`
+	}
+	re, err := regexp.Compile("^" + expr + "$")
+	if err != nil {
+		return true
+	}
+	for _, pkg := range pkgs {
+		s := pkg.PkgPath
+		if local {
+			s = filepath.ToSlash(realpath(pkg.Dir))
+		}
+		if re.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
+func realpath(path string) string {
+	if p, err := filepath.EvalSymlinks(path); err == nil {
+		return p
+	}
+	return path
+}
+
+// modules returns the module paths of pkg and of its imports by import path.
+func modules(pkg *packages.Package) map[string]string {
+	ms := map[string]string{}
+	if pkg.Module != nil {
+		ms[pkg.PkgPath] = pkg.Module.Path
+	}
+	for _, imp := range pkg.Imports {
+		if imp.Module != nil {
+			ms[imp.PkgPath] = imp.Module.Path
+		}
+	}
+	return ms
+}
+
+type importerFunc func(path string) (*types.Package, error)
+
+func (f importerFunc) Import(path string) (*types.Package, error) {
+	return f(path)
 }
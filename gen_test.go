@@ -0,0 +1,316 @@
+package gouml_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-kit/kit/log"
+	"github.com/kazukousen/gouml"
+)
+
+// module writes the files of a module in a temporary directory, changes
+// into it and returns it.
+func module(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, src := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf(": %+v", err)
+		}
+		if err := os.WriteFile(path, []byte(src), 0644); err != nil {
+			t.Fatalf(": %+v", err)
+		}
+	}
+	t.Chdir(dir)
+	return dir
+}
+
+func TestGeneratorRead(t *testing.T) {
+	module(t, map[string]string{
+		"go.mod":          "module shop\n\ngo 1.21\n",
+		"domain/user.go":  "package domain\n\ntype User struct{ ID int }\n",
+		"svc/a.go":        "package svc\n\nimport \"shop/domain\"\n\ntype A struct{ U domain.User }\n",
+		"svc/b.go":        "package svc\n\ntype B struct{}\n",
+		"infra/db.go":     "package infra\n\ntype DB struct{}\n",
+		"infra/mock.go":   "package infra\n\ntype Mock struct{}\n",
+		"infra/README.md": "infra\n",
+	})
+
+	tests := []struct {
+		name    string
+		targets []string
+		ignores []string
+		want    []string
+		notWant []string
+	}{
+		{
+			name:    "pattern",
+			targets: []string{"./..."},
+			want:    []string{`class "User"`, `class "A"`, `class "B"`, `class "DB"`, `class "Mock"`},
+		},
+		{
+			name:    "import path",
+			targets: []string{"shop/infra"},
+			want:    []string{`class "DB"`},
+			notWant: []string{`class "A"`, `class "User"`},
+		},
+		{
+			name:    "directory",
+			targets: []string{"svc"},
+			want:    []string{`class "A"`, `class "B"`},
+			notWant: []string{`class "DB"`, `class "User"`},
+		},
+		{
+			// the other files of the package are left out, and the imports
+			// are still resolved
+			name:    "file",
+			targets: []string{"svc/a.go"},
+			want:    []string{`class "A"`, "domain.User"},
+			notWant: []string{`class "B"`, "invalid type"},
+		},
+		{
+			name:    "ignore",
+			targets: []string{"./..."},
+			ignores: []string{"infra/mock.go"},
+			want:    []string{`class "DB"`},
+			notWant: []string{`class "Mock"`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gen := gouml.NewGenerator(log.NewNopLogger(), gouml.PlantUMLParser(log.NewNopLogger(), gouml.Options{}), false)
+			gen.SetStrict(true)
+			if err := gen.UpdateIgnore(tt.ignores); err != nil {
+				t.Fatalf(": %+v", err)
+			}
+			if err := gen.Read(tt.targets); err != nil {
+				t.Fatalf(": %+v", err)
+			}
+			buf := &bytes.Buffer{}
+			if _, err := gen.WriteTo(buf); err != nil {
+				t.Fatalf(": %+v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("want %s, got\n%s", want, buf.String())
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(buf.String(), notWant) {
+					t.Errorf("do not want %s, got\n%s", notWant, buf.String())
+				}
+			}
+		})
+	}
+}
+
+func TestGeneratorReadOutside(t *testing.T) {
+	dir := module(t, map[string]string{
+		"go.mod":         "module shop\n\ngo 1.21\n",
+		"domain/user.go": "package domain\n\ntype User struct{ ID int }\n",
+		"svc/a.go":       "package svc\n\nimport \"shop/domain\"\n\ntype A struct{ U domain.User }\n",
+		"infra/db.go":    "package infra\n\ntype DB struct{}\n",
+		"infra/mock.go":  "package infra\n\ntype Mock struct{}\n",
+	})
+	// far from the module
+	t.Chdir(t.TempDir())
+
+	gen := gouml.NewGenerator(log.NewNopLogger(), gouml.PlantUMLParser(log.NewNopLogger(), gouml.Options{}), false)
+	gen.SetStrict(true)
+	if err := gen.Read([]string{filepath.Join(dir, "domain"), filepath.Join(dir, "svc", "a.go")}); err != nil {
+		t.Fatalf(": %+v", err)
+	}
+	buf := &bytes.Buffer{}
+	if _, err := gen.WriteTo(buf); err != nil {
+		t.Fatalf(": %+v", err)
+	}
+	for _, want := range []string{`class "User"`, `class "A"`} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("want %s, got\n%s", want, buf.String())
+		}
+	}
+	if strings.Contains(buf.String(), `class "DB"`) {
+		t.Errorf("got\n%s", buf.String())
+	}
+}
+
+func TestGeneratorReadMissing(t *testing.T) {
+	module(t, map[string]string{
+		"go.mod": "module shop\n\ngo 1.21\n",
+	})
+
+	gen := gouml.NewGenerator(log.NewNopLogger(), gouml.PlantUMLParser(log.NewNopLogger(), gouml.Options{}), false)
+	if err := gen.Read([]string{"./nosuchdir"}); !os.IsNotExist(err) {
+		t.Errorf("got %v", err)
+	}
+}
+
+func TestGeneratorSamePackageName(t *testing.T) {
+	module(t, map[string]string{
+		"go.mod":           "module shop\n\ngo 1.21\n",
+		"a/config/conf.go": "package config\n\ntype Config struct{ A int }\n",
+		"b/config/conf.go": "package config\n\ntype Config struct{ B string }\n",
+	})
+
+	gen := gouml.NewGenerator(log.NewNopLogger(), gouml.PlantUMLParser(log.NewNopLogger(), gouml.Options{}), false)
+	if err := gen.Read([]string{"./..."}); err != nil {
+		t.Fatalf(": %+v", err)
+	}
+	buf := &bytes.Buffer{}
+	if _, err := gen.WriteTo(buf); err != nil {
+		t.Fatalf(": %+v", err)
+	}
+	for _, want := range []string{
+		`package "shop/a/config"`,
+		`package "shop/b/config"`,
+		`class "Config" as shop_sa_sconfig_dConfig`,
+		`class "Config" as shop_sb_sconfig_dConfig`,
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("want %s, got\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestGeneratorWriteToTwice(t *testing.T) {
+	module(t, map[string]string{
+		"go.mod":    "module shop\n\ngo 1.21\n",
+		"infra.go":  "package shop\n\ntype DB struct{ C Missing }\n",
+		"README.md": "shop\n",
+	})
+
+	gen := gouml.NewGenerator(log.NewNopLogger(), gouml.PlantUMLParser(log.NewNopLogger(), gouml.Options{}), false)
+	if err := gen.Read([]string{".", "README.md"}); err != nil {
+		t.Fatalf(": %+v", err)
+	}
+	first := &bytes.Buffer{}
+	if _, err := gen.WriteTo(first); err != nil {
+		t.Fatalf(": %+v", err)
+	}
+	diags := gen.Diagnostics()
+	second := &bytes.Buffer{}
+	if _, err := gen.WriteTo(second); err != nil {
+		t.Fatalf(": %+v", err)
+	}
+	if first.String() != second.String() {
+		t.Errorf("got\n%s\nthen\n%s", first.String(), second.String())
+	}
+	if n := strings.Count(second.String(), `class "DB"`); n != 1 {
+		t.Errorf("got %d DB", n)
+	}
+	// the skipped README.md, the type error and the invalid type
+	if len(diags) != 3 || !reflect.DeepEqual(gen.Diagnostics(), diags) {
+		t.Errorf("got %v, then %v", diags, gen.Diagnostics())
+	}
+}
+
+func TestGeneratorUnresolvedPackages(t *testing.T) {
+	module(t, map[string]string{
+		"go.mod":          "module shop\n\ngo 1.21\n",
+		"empty/README.md": "empty\n",
+	})
+
+	gen := gouml.NewGenerator(log.NewNopLogger(), gouml.PlantUMLParser(log.NewNopLogger(), gouml.Options{}), false)
+	gen.SetStrict(true)
+	if err := gen.Read([]string{"./empty", "shop/typo"}); err != nil {
+		t.Fatalf(": %+v", err)
+	}
+	_, err := gen.WriteTo(&bytes.Buffer{})
+	if err == nil || !strings.Contains(err.Error(), "empty/...: matched no packages") {
+		t.Errorf("strict: got %v", err)
+	}
+	ds := gen.Diagnostics()
+	if len(ds) != 2 {
+		t.Fatalf("got %v", ds)
+	}
+	for _, d := range ds {
+		if d.Kind != gouml.DiagnosticUnresolvedPackage {
+			t.Errorf("got %s: %s", d.Kind, d)
+		}
+	}
+	if ds[1].Package != "shop/typo" {
+		t.Errorf("got %s", ds[1].Package)
+	}
+}
+
+func TestGeneratorTypeErrors(t *testing.T) {
+	module(t, map[string]string{
+		"go.mod": "module bad\n\ngo 1.21\n",
+		"a.go":   "package bad\n\ntype A struct{ B Missing }\n",
+	})
+
+	for _, strict := range []bool{false, true} {
+		gen := gouml.NewGenerator(log.NewNopLogger(), gouml.PlantUMLParser(log.NewNopLogger(), gouml.Options{}), false)
+		gen.SetStrict(strict)
+		if err := gen.Read([]string{"."}); err != nil {
+			t.Fatalf(": %+v", err)
+		}
+		buf := &bytes.Buffer{}
+		_, err := gen.WriteTo(buf)
+		if !strict {
+			if err != nil {
+				t.Errorf("lenient: %+v", err)
+			}
+			if !strings.Contains(buf.String(), "+B: invalid type") {
+				t.Errorf("lenient: got %s", buf.String())
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), "a.go:3:18: undefined: Missing") {
+			t.Errorf("strict: got %v", err)
+		}
+	}
+}
+
+func TestGeneratorDiagnostics(t *testing.T) {
+	module(t, map[string]string{
+		"go.mod":    "module bad\n\ngo 1.21\n",
+		"a.go":      "package bad\n\ntype A struct{ B Missing }\n",
+		"a_test.go": "package bad\n",
+		"b.go":      "//go:build ignore\n\npackage bad\n",
+	})
+
+	gen := gouml.NewGenerator(log.NewNopLogger(), gouml.PlantUMLParser(log.NewNopLogger(), gouml.Options{}), false)
+	if err := gen.Read([]string{".", "a_test.go"}); err != nil {
+		t.Fatalf(": %+v", err)
+	}
+	if _, err := gen.WriteTo(&bytes.Buffer{}); err != nil {
+		t.Fatalf(": %+v", err)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := gouml.WriteDiagnostics(buf, gen.Diagnostics(), "text"); err != nil {
+		t.Fatalf(": %+v", err)
+	}
+	for _, want := range []string{
+		"a_test.go: test file (skipped_file)",
+		"b.go: excluded by build constraints (skipped_file)",
+		"a.go:3:18: undefined: Missing (type_error)",
+		"a.go:3:16: field A.B has an invalid type (invalid_type)",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("want %q, got\n%s", want, buf.String())
+		}
+	}
+
+	buf.Reset()
+	if err := gouml.WriteDiagnostics(buf, gen.Diagnostics(), "json"); err != nil {
+		t.Fatalf(": %+v", err)
+	}
+	var ds []gouml.Diagnostic
+	if err := json.Unmarshal(buf.Bytes(), &ds); err != nil {
+		t.Fatalf(": %+v", err)
+	}
+	if len(ds) != len(gen.Diagnostics()) {
+		t.Errorf("json: got %d diagnostics, want %d", len(ds), len(gen.Diagnostics()))
+	}
+	if err := gouml.WriteDiagnostics(buf, nil, "xml"); err == nil {
+		t.Errorf("xml: want an error")
+	}
+}
@@ -2,9 +2,16 @@ module github.com/kazukousen/gouml
 
 require (
 	github.com/go-kit/kit v0.9.0
+	github.com/urfave/cli v1.20.0
+	golang.org/x/tools v0.50.0
+)
+
+require (
 	github.com/go-logfmt/logfmt v0.4.0 // indirect
 	github.com/go-stack/stack v1.8.0 // indirect
-	github.com/urfave/cli v1.20.0
+	github.com/kr/logfmt v0.0.0-20140226030751-b84e30acd515 // indirect
+	golang.org/x/mod v0.41.0 // indirect
+	golang.org/x/sync v0.23.0 // indirect
 )
 
-go 1.13
+go 1.26.0
@@ -0,0 +1,38 @@
+package dot
+
+import (
+	"bufio"
+
+	"github.com/kazukousen/gouml/model"
+)
+
+// edge styles of the relations, matching the arrows of the PlantUML backend.
+var edgeStyles = map[model.EdgeKind]string{
+	model.EdgeField:        `arrowhead=vee`,
+	model.EdgeUse:          `arrowhead=vee, style=dashed, label="use"`,
+	model.EdgeReturn:       `arrowhead=vee, style=dashed, label="return"`,
+	model.EdgeWrap:         `dir=back, arrowtail=diamond`,
+	model.EdgeImplements:   `arrowhead=empty, style=dashed`,
+	model.EdgeEmbed:        `dir=back, arrowtail=diamond, label="embed"`,
+	model.EdgeExtend:       `arrowhead=empty, label="embed"`,
+	model.EdgeAlias:        `arrowhead=vee, style=dashed, label="alias"`,
+	model.EdgeConstraint:   `arrowhead=vee, style=dashed, label="constraint"`,
+	model.EdgeTypeArgument: `arrowhead=vee, style=dashed, label="bind"`,
+}
+
+const edgeNote = `[arrowhead=none, style=dotted]`
+
+func writeEdge(buf *bufio.Writer, e *model.Edge, depth int) {
+	newline(buf, depth)
+	buf.WriteString(e.From)
+	buf.WriteString(" -> ")
+	buf.WriteString(e.To)
+	buf.WriteString(" [")
+	buf.WriteString(edgeStyles[e.Kind])
+	if e.Multiplicity != "" {
+		buf.WriteString(`, headlabel="`)
+		buf.WriteString(string(e.Multiplicity))
+		buf.WriteString(`"`)
+	}
+	buf.WriteString("];")
+}
@@ -0,0 +1,36 @@
+package dot
+
+import (
+	"github.com/kazukousen/gouml/model"
+)
+
+// fills are the background colors of the nodes, same as the PlantUML spots.
+var fills = map[model.Kind]string{
+	model.KindInterface:   "white",
+	model.KindValueObject: "orchid",
+	model.KindEntity:      "#FFCC00",
+	model.KindClass:       "white",
+	model.KindConstraint:  "white",
+	model.KindAlias:       "white",
+	model.KindUtility:     "lightgrey",
+	model.KindGlobals:     "lightgrey",
+	model.KindEnum:        "white",
+}
+
+// stereotypes are shown above the names of the nodes, except for the classes.
+var stereotypes = map[model.Kind]string{
+	model.KindInterface:  "interface",
+	model.KindConstraint: "constraint",
+	model.KindAlias:      "alias",
+	model.KindUtility:    "utility",
+	model.KindGlobals:    "globals",
+	model.KindEnum:       "enumeration",
+}
+
+// roles are the stereotypes and the fills of the roles, which take
+// precedence over the ones of the kinds.
+var roles = map[model.Role][2]string{
+	model.RoleRepository: {"repository", "#7FB3D5"},
+	model.RoleService:    {"service", "#82E0AA"},
+	model.RoleFactory:    {"factory", "#F8C471"},
+}
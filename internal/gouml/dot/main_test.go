@@ -0,0 +1,12 @@
+package dot_test
+
+func trim(src string) string {
+	dst := make([]byte, 0, len(src))
+	for _, ch := range src {
+		if ch == '\t' {
+			continue
+		}
+		dst = append(dst, byte(ch))
+	}
+	return string(dst)
+}
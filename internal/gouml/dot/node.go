@@ -0,0 +1,133 @@
+package dot
+
+import (
+	"bufio"
+
+	"github.com/kazukousen/gouml/model"
+)
+
+func writeNode(buf *bufio.Writer, n *model.Node, depth int) {
+	newline(buf, depth)
+	buf.WriteString(n.ID)
+	buf.WriteString(` [label="{`)
+	stereotype, fill := stereotypes[n.Kind], fills[n.Kind]
+	if r, ok := roles[n.Role]; ok {
+		stereotype, fill = r[0], r[1]
+	}
+	if n.Stereotype != "" {
+		stereotype = n.Stereotype
+	}
+	if stereotype != "" {
+		buf.WriteString(`\<\<`)
+		buf.WriteString(record.Replace(stereotype))
+		buf.WriteString(`\>\>\n`)
+	}
+	buf.WriteString(record.Replace(n.Title()))
+	if n.Aliased != "" {
+		buf.WriteString("|= ")
+		buf.WriteString(record.Replace(n.Aliased))
+		buf.WriteString(`\l`)
+	}
+	if len(n.Fields) > 0 || len(n.Methods) > 0 || len(n.Terms) > 0 || len(n.Constants) > 0 {
+		buf.WriteString("|")
+		for _, t := range n.Terms {
+			buf.WriteString(record.Replace(t))
+			buf.WriteString(`\l`)
+		}
+		for _, c := range n.Constants {
+			writeConstant(buf, c)
+		}
+		for _, f := range n.Fields {
+			writeField(buf, f)
+		}
+		buf.WriteString("|")
+		for _, m := range n.Methods {
+			writeMethod(buf, m)
+		}
+	}
+	if n.Color != "" {
+		fill = n.Color
+	}
+	buf.WriteString(`}", fillcolor="`)
+	buf.WriteString(fill)
+	buf.WriteString(`"];`)
+}
+
+func writeConstant(buf *bufio.Writer, c *model.Constant) {
+	buf.WriteString(record.Replace(c.Name))
+	buf.WriteString(" = ")
+	buf.WriteString(record.Replace(c.Value))
+	writeDoc(buf, c.Doc)
+	buf.WriteString(`\l`)
+}
+
+// writeDoc trails a member with its comment.
+func writeDoc(buf *bufio.Writer, doc string) {
+	if doc == "" {
+		return
+	}
+	buf.WriteString(" // ")
+	buf.WriteString(record.Replace(doc))
+}
+
+func writeField(buf *bufio.Writer, f *model.Field) {
+	buf.WriteString(exportedIcon(f.Exported))
+	buf.WriteString(record.Replace(f.Name))
+	buf.WriteString(": ")
+	buf.WriteString(record.Replace(f.Type))
+	if f.Tag != "" {
+		buf.WriteString(" `")
+		buf.WriteString(record.Replace(f.Tag))
+		buf.WriteString("`")
+	}
+	writeDoc(buf, f.Doc)
+	buf.WriteString(`\l`)
+}
+
+func writeMethod(buf *bufio.Writer, m *model.Method) {
+	if m.Static {
+		buf.WriteString(`\<\<create\>\> `)
+	}
+	buf.WriteString(exportedIcon(m.Exported))
+	// Name, qualified by the embedded type it is promoted from
+	if m.PromotedFrom != "" {
+		buf.WriteString(record.Replace(m.PromotedFrom))
+		buf.WriteString(".")
+	}
+	buf.WriteString(m.Name)
+
+	// parameters
+	buf.WriteString("(")
+	for i, p := range m.Params {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(p.Name)
+		buf.WriteString(": ")
+		buf.WriteString(record.Replace(p.Type))
+	}
+	buf.WriteString(")")
+
+	// results
+	if len(m.Results) > 0 {
+		buf.WriteString(": ")
+	}
+	if len(m.Results) > 1 {
+		buf.WriteString("(")
+	}
+	for i, p := range m.Results {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		if p.Name != "" {
+			buf.WriteString(p.Name)
+			buf.WriteString(": ")
+		}
+		buf.WriteString(record.Replace(p.Type))
+	}
+	if len(m.Results) > 1 {
+		buf.WriteString(")")
+	}
+	writeDoc(buf, m.Doc)
+	buf.WriteString(`\l`)
+}
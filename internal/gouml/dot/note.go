@@ -0,0 +1,35 @@
+package dot
+
+import (
+	"bufio"
+
+	"github.com/kazukousen/gouml/internal/gouml/naming"
+	"github.com/kazukousen/gouml/model"
+)
+
+func noteID(n *model.Note) string {
+	switch n.Kind {
+	case model.NoteDoc:
+		return "D_" + n.Node
+	case model.NoteText:
+		return "T_" + n.Node
+	}
+	if n.Member != "" {
+		// a node may have a note for each of its members
+		return "N_" + n.Node + "_m" + naming.EscapeID(n.Member)
+	}
+	return "N_" + n.Node
+}
+
+func writeNote(buf *bufio.Writer, n *model.Note, depth int) {
+	newline(buf, depth)
+	buf.WriteString(noteID(n))
+	buf.WriteString(` [shape=note, label="`)
+	buf.WriteString(record.Replace(n.Title))
+	buf.WriteString(`\n\n`)
+	for _, line := range n.Lines {
+		buf.WriteString(record.Replace(line))
+		buf.WriteString(`\l`)
+	}
+	buf.WriteString(`"];`)
+}
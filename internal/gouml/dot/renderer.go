@@ -0,0 +1,86 @@
+package dot
+
+import (
+	"io"
+
+	"github.com/kazukousen/gouml/internal/gouml/naming"
+	"github.com/kazukousen/gouml/internal/gouml/writer"
+	"github.com/kazukousen/gouml/model"
+)
+
+// Renderer writes a model.Graph as the statements of a Graphviz digraph.
+type Renderer struct{}
+
+// NewRenderer ...
+func NewRenderer() Renderer {
+	return Renderer{}
+}
+
+// Render ...
+func (r Renderer) Render(w io.Writer, g *model.Graph) (int64, error) {
+	out := writer.New(w)
+	buf := out.Writer
+	newline(buf, 1)
+	buf.WriteString("rankdir=BT;")
+	newline(buf, 1)
+	buf.WriteString(`node [shape=record, style=filled, fontname="Helvetica"];`)
+	newline(buf, 1)
+	buf.WriteString(`edge [fontname="Helvetica"];`)
+
+	// one cluster per package
+	for _, pkg := range g.Packages {
+		nodes, notes := g.NodesOf(pkg), g.NotesOf(pkg)
+		if len(nodes) == 0 && len(notes) == 0 {
+			continue
+		}
+		newline(buf, 0)
+		newline(buf, 1)
+		buf.WriteString("subgraph cluster_")
+		buf.WriteString(naming.EscapeID(pkg.Path))
+		buf.WriteString(" {")
+		newline(buf, 2)
+		buf.WriteString(`label="`)
+		buf.WriteString(record.Replace(pkg.Path))
+		buf.WriteString(`";`)
+		rest, layers := model.ByLayer(nodes)
+		for _, n := range rest {
+			writeNode(buf, n, 2)
+		}
+		for _, l := range layers {
+			newline(buf, 2)
+			buf.WriteString("subgraph cluster_")
+			buf.WriteString(naming.EscapeID(pkg.Path + "#" + l.Name))
+			buf.WriteString(" {")
+			newline(buf, 3)
+			buf.WriteString(`label="`)
+			buf.WriteString(record.Replace(l.Name))
+			buf.WriteString(`"; style=dashed;`)
+			for _, n := range l.Nodes {
+				writeNode(buf, n, 3)
+			}
+			newline(buf, 2)
+			buf.WriteString("}")
+		}
+		for _, n := range notes {
+			writeNote(buf, n, 2)
+		}
+		newline(buf, 1)
+		buf.WriteString("}")
+	}
+
+	newline(buf, 0)
+	for _, e := range g.Edges {
+		writeEdge(buf, e, 1)
+	}
+	for _, n := range g.Notes {
+		newline(buf, 1)
+		buf.WriteString(noteID(n))
+		buf.WriteString(" -> ")
+		buf.WriteString(n.Node)
+		buf.WriteString(" ")
+		buf.WriteString(edgeNote)
+		buf.WriteString(";")
+	}
+	newline(buf, 0)
+	return out.Flush()
+}
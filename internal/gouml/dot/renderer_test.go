@@ -0,0 +1,77 @@
+package dot_test
+
+import (
+	"bytes"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+
+	"github.com/kazukousen/gouml/internal/gouml/dot"
+	"github.com/kazukousen/gouml/model"
+)
+
+func TestRenderer(t *testing.T) {
+	fset := token.NewFileSet()
+	src := `
+	package shop
+	type Status int
+	const (
+		Open Status = iota
+		Closed
+	)
+	type Item struct {
+		Name   string
+		Status Status
+	}
+	type Items []Item
+	type Finder interface {
+		Find(name string) (*Item, error)
+	}
+	type repository struct {
+		items map[string]Item
+	}
+	func (r *repository) Find(name string) (*Item, error) { return nil, nil }
+	`
+	want := `
+	rankdir=BT;
+	node [shape=record, style=filled, fontname="Helvetica"];
+	edge [fontname="Helvetica"];
+
+	subgraph cluster_shop {
+		label="shop";
+		shop_dFinder [label="{\<\<interface\>\>\nFinder||+Find(name: string): (*shop.Item, error)\l}", fillcolor="white"];
+		shop_dItem [label="{Item|+Name: string\l+Status: shop.Status\l|}", fillcolor="orchid"];
+		shop_dItems [label="{Items}", fillcolor="orchid"];
+		shop_dStatus [label="{\<\<enumeration\>\>\nStatus|Open = 0\lClosed = 1\l|}", fillcolor="white"];
+		shop_drepository [label="{repository|-items: map[string]shop.Item\l|+Find(name: string): (*shop.Item, error)\l}", fillcolor="orchid"];
+	}
+
+	shop_dFinder -> shop_dItem [arrowhead=vee, style=dashed, label="return"];
+	shop_dItem -> shop_dStatus [arrowhead=vee, headlabel="1"];
+	shop_dItems -> shop_dItem [dir=back, arrowtail=diamond, headlabel="*"];
+	shop_drepository -> shop_dItem [arrowhead=vee, headlabel="*"];
+	shop_drepository -> shop_dItem [arrowhead=vee, style=dashed, label="return"];
+	shop_drepository -> shop_dFinder [arrowhead=empty, style=dashed];
+`
+	file, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		t.Errorf(": %+v", err)
+		return
+	}
+	conf := types.Config{
+		Importer: importer.Default(),
+	}
+	pkg, err := conf.Check(file.Name.Name, fset, []*ast.File{file}, nil)
+	if err != nil {
+		t.Errorf(": %+v", err)
+		return
+	}
+	buf := &bytes.Buffer{}
+	dot.NewRenderer().Render(buf, model.Build([]*types.Package{pkg}, model.Options{}))
+	if g, w := trim(buf.String()), trim(want); g != w {
+		t.Errorf("not equal\ngot: %s\nwant: %s", g, w)
+	}
+}
@@ -0,0 +1,31 @@
+package dot
+
+import (
+	"bufio"
+	"strings"
+)
+
+func newline(dst *bufio.Writer, depth int) {
+	dst.WriteString("\n")
+	for i := 0; i < depth; i++ {
+		dst.WriteString("\t")
+	}
+}
+
+func exportedIcon(exported bool) string {
+	if exported {
+		return "+"
+	}
+	return "-"
+}
+
+// record escapes the characters that have a meaning in a record label.
+var record = strings.NewReplacer(
+	`\`, `\\`,
+	"{", `\{`,
+	"}", `\}`,
+	"|", `\|`,
+	"<", `\<`,
+	">", `\>`,
+	`"`, `\"`,
+)
@@ -0,0 +1,119 @@
+// Package jsonschema writes a model.Graph as a schema.Document in JSON.
+package jsonschema
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/kazukousen/gouml/internal/gouml/writer"
+	"github.com/kazukousen/gouml/model"
+	"github.com/kazukousen/gouml/schema"
+)
+
+// Renderer ...
+type Renderer struct{}
+
+// NewRenderer ...
+func NewRenderer() Renderer {
+	return Renderer{}
+}
+
+// Render ...
+func (r Renderer) Render(w io.Writer, g *model.Graph) (int64, error) {
+	out := writer.New(w)
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(Document(g)); err != nil {
+		return 0, err
+	}
+	return out.Flush()
+}
+
+// Document converts g to the versioned schema.
+func Document(g *model.Graph) schema.Document {
+	doc := schema.Document{
+		Version:   schema.Version,
+		Packages:  []schema.Package{},
+		Models:    []schema.Model{},
+		Relations: []schema.Relation{},
+		Notes:     []schema.Note{},
+	}
+	for _, pkg := range g.Packages {
+		doc.Packages = append(doc.Packages, schema.Package{Path: pkg.Path, Name: pkg.Name, Module: pkg.Module})
+	}
+	for _, n := range g.Nodes {
+		doc.Models = append(doc.Models, node(n))
+	}
+	for _, e := range g.Edges {
+		doc.Relations = append(doc.Relations, schema.Relation{
+			From:         e.From,
+			To:           e.To,
+			Kind:         schema.RelationKind(e.Kind),
+			Multiplicity: string(e.Multiplicity),
+		})
+	}
+	for _, n := range g.Notes {
+		note := schema.Note{Model: n.Node, Member: n.Member}
+		switch n.Kind {
+		case model.NoteConstants:
+			note.Constants = n.Lines
+		case model.NoteErrors:
+			note.Errors = n.Lines
+		case model.NoteText:
+			note.Text = n.Lines
+		case model.NoteDoc:
+			// exported as the Doc of the Model
+			continue
+		}
+		doc.Notes = append(doc.Notes, note)
+	}
+	return doc
+}
+
+func node(n *model.Node) schema.Model {
+	m := schema.Model{
+		ID:         n.ID,
+		Name:       n.Name,
+		Package:    n.Package.Path,
+		Kind:       schema.Kind(n.Kind),
+		TypeParams: []schema.TypeParam{},
+		Fields:     []schema.Field{},
+		Methods:    []schema.Method{},
+		Terms:      n.Terms,
+		Aliased:    n.Aliased,
+		Doc:        n.Doc,
+		Stereotype: n.Stereotype,
+		Color:      n.Color,
+		Role:       string(n.Role),
+		Layer:      n.Layer,
+	}
+	for _, c := range n.Constants {
+		m.Constants = append(m.Constants, schema.Constant{Name: c.Name, Value: c.Value, Doc: c.Doc})
+	}
+	for _, tp := range n.TypeParams {
+		m.TypeParams = append(m.TypeParams, schema.TypeParam{Name: tp.Name, Constraint: tp.Constraint})
+	}
+	for _, f := range n.Fields {
+		m.Fields = append(m.Fields, schema.Field{Name: f.Name, Type: f.Type, Exported: f.Exported, Embedded: f.Embedded, Tag: f.Tag, Doc: f.Doc})
+	}
+	for _, mt := range n.Methods {
+		m.Methods = append(m.Methods, schema.Method{
+			Name:         mt.Name,
+			Exported:     mt.Exported,
+			Static:       mt.Static,
+			Params:       params(mt.Params),
+			Results:      params(mt.Results),
+			PromotedFrom: mt.PromotedFrom,
+			Doc:          mt.Doc,
+		})
+	}
+	return m
+}
+
+func params(ps []*model.Param) []schema.Param {
+	dst := make([]schema.Param, 0, len(ps))
+	for _, p := range ps {
+		dst = append(dst, schema.Param{Name: p.Name, Type: p.Type})
+	}
+	return dst
+}
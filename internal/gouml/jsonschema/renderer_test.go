@@ -0,0 +1,79 @@
+package jsonschema_test
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"reflect"
+	"testing"
+
+	"github.com/kazukousen/gouml/internal/gouml/jsonschema"
+	"github.com/kazukousen/gouml/model"
+	"github.com/kazukousen/gouml/schema"
+)
+
+func TestDocument(t *testing.T) {
+	fset := token.NewFileSet()
+	src := `
+	package shop
+	type Status int
+	const (
+		Open Status = iota
+		Closed
+	)
+	type Item struct {
+		Status Status
+	}
+	type Finder interface {
+		Find(name string) (*Item, error)
+	}
+	type repository map[string]Item
+	func (r repository) Find(name string) (*Item, error) { return nil, nil }
+	`
+	file, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		t.Errorf(": %+v", err)
+		return
+	}
+	conf := types.Config{
+		Importer: importer.Default(),
+	}
+	pkg, err := conf.Check(file.Name.Name, fset, []*ast.File{file}, nil)
+	if err != nil {
+		t.Errorf(": %+v", err)
+		return
+	}
+	doc := jsonschema.Document(model.Build([]*types.Package{pkg}, model.Options{}))
+
+	if doc.Version != schema.Version {
+		t.Errorf("version: got %s, want %s", doc.Version, schema.Version)
+	}
+	if want := []schema.Package{{Path: "shop", Name: "shop"}}; !reflect.DeepEqual(doc.Packages, want) {
+		t.Errorf("packages: got %+v, want %+v", doc.Packages, want)
+	}
+	wantRelations := []schema.Relation{
+		{From: "shop_dFinder", To: "shop_dItem", Kind: schema.RelationReturn},
+		{From: "shop_dItem", To: "shop_dStatus", Kind: schema.RelationField, Multiplicity: "1"},
+		{From: "shop_drepository", To: "shop_dItem", Kind: schema.RelationWrap, Multiplicity: "*"},
+		{From: "shop_drepository", To: "shop_dItem", Kind: schema.RelationReturn},
+		{From: "shop_drepository", To: "shop_dFinder", Kind: schema.RelationImplements},
+	}
+	if !reflect.DeepEqual(doc.Relations, wantRelations) {
+		t.Errorf("relations: got %+v, want %+v", doc.Relations, wantRelations)
+	}
+	wantConstants := []schema.Constant{{Name: "Open", Value: "0"}, {Name: "Closed", Value: "1"}}
+	if got := doc.Models[2]; got.Kind != schema.KindEnum || !reflect.DeepEqual(got.Constants, wantConstants) {
+		t.Errorf("enum: got %+v", got)
+	}
+
+	doc = jsonschema.Document(model.Build([]*types.Package{pkg}, model.Options{ConstantNotes: true}))
+	wantNotes := []schema.Note{{Model: "shop_dStatus", Constants: []string{"Closed", "Open"}}}
+	if !reflect.DeepEqual(doc.Notes, wantNotes) {
+		t.Errorf("notes: got %+v, want %+v", doc.Notes, wantNotes)
+	}
+	if got := doc.Models[0]; got.Kind != schema.KindInterface || len(got.Methods) != 1 || len(got.Methods[0].Results) != 2 {
+		t.Errorf("model: got %+v", got)
+	}
+}
@@ -0,0 +1,51 @@
+package mermaid
+
+import (
+	"bufio"
+
+	"github.com/kazukousen/gouml/model"
+)
+
+var edgeArrows = map[model.EdgeKind]string{
+	model.EdgeField:        " --> ",
+	model.EdgeUse:          " ..> ",
+	model.EdgeReturn:       " ..> ",
+	model.EdgeWrap:         " *-- ",
+	model.EdgeEmbed:        " *-- ",
+	model.EdgeExtend:       " --|> ",
+	model.EdgeAlias:        " ..> ",
+	model.EdgeConstraint:   " ..> ",
+	model.EdgeTypeArgument: " ..> ",
+}
+
+var edgeLabels = map[model.EdgeKind]string{
+	model.EdgeUse:          " : use",
+	model.EdgeReturn:       " : return",
+	model.EdgeEmbed:        " : embed",
+	model.EdgeExtend:       " : embed",
+	model.EdgeAlias:        " : alias",
+	model.EdgeConstraint:   " : constraint",
+	model.EdgeTypeArgument: " : bind",
+}
+
+func writeEdge(buf *bufio.Writer, g *model.Graph, e *model.Edge, depth int) {
+	arrow := edgeArrows[e.Kind]
+	if e.Kind == model.EdgeImplements {
+		arrow = " ..|> "
+		if g.Node(e.From).Kind == model.KindInterface {
+			// an interface extends another interface
+			arrow = " --|> "
+		}
+	}
+
+	newline(buf, depth)
+	buf.WriteString(e.From)
+	buf.WriteString(arrow)
+	if e.Multiplicity != "" {
+		buf.WriteString(`"`)
+		buf.WriteString(string(e.Multiplicity))
+		buf.WriteString(`" `)
+	}
+	buf.WriteString(e.To)
+	buf.WriteString(edgeLabels[e.Kind])
+}
@@ -0,0 +1,50 @@
+package mermaid
+
+import (
+	"github.com/kazukousen/gouml/model"
+)
+
+type modelKind string
+
+const (
+	modelKindInterface   modelKind = "interface"
+	modelKindValueObject modelKind = "ValueObject"
+	modelKindEntity      modelKind = "Entity"
+	modelKindClass       modelKind = ""
+	modelKindConstraint  modelKind = "constraint"
+	modelKindAlias       modelKind = "alias"
+	modelKindUtility     modelKind = "utility"
+	modelKindGlobals     modelKind = "globals"
+	modelKindEnum        modelKind = "enumeration"
+)
+
+var modelKinds = map[model.Kind]modelKind{
+	model.KindInterface:   modelKindInterface,
+	model.KindValueObject: modelKindValueObject,
+	model.KindEntity:      modelKindEntity,
+	model.KindClass:       modelKindClass,
+	model.KindConstraint:  modelKindConstraint,
+	model.KindAlias:       modelKindAlias,
+	model.KindUtility:     modelKindUtility,
+	model.KindGlobals:     modelKindGlobals,
+	model.KindEnum:        modelKindEnum,
+}
+
+// fill returns the background color of the class, same as the PlantUML spots.
+func (k modelKind) fill() string {
+	switch k {
+	case modelKindValueObject:
+		return "#DA70D6"
+	case modelKindEntity:
+		return "#FFCC00"
+	}
+	return ""
+}
+
+// roles are the stereotypes and the fills of the roles, which take
+// precedence over the ones of the kinds.
+var roles = map[model.Role][2]string{
+	model.RoleRepository: {"Repository", "#7FB3D5"},
+	model.RoleService:    {"Service", "#82E0AA"},
+	model.RoleFactory:    {"Factory", "#F8C471"},
+}
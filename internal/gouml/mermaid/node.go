@@ -0,0 +1,142 @@
+package mermaid
+
+import (
+	"bufio"
+
+	"github.com/kazukousen/gouml/model"
+)
+
+func writeNode(buf *bufio.Writer, n *model.Node, depth int) {
+	kind := modelKinds[n.Kind]
+
+	// class, labeled with the package name as Mermaid has no package blocks
+	newline(buf, depth)
+	buf.WriteString("class ")
+	buf.WriteString(n.ID)
+	buf.WriteString(`["`)
+	buf.WriteString(n.Package.Name)
+	buf.WriteString(".")
+	buf.WriteString(entities.Replace(n.Title()))
+	buf.WriteString(`"] {`)
+	stereotype, fill := string(kind), kind.fill()
+	if r, ok := roles[n.Role]; ok {
+		stereotype, fill = r[0], r[1]
+	}
+	if n.Stereotype != "" {
+		stereotype = entities.Replace(n.Stereotype)
+	}
+	if stereotype != "" {
+		newline(buf, depth+1)
+		buf.WriteString("<<")
+		buf.WriteString(stereotype)
+		buf.WriteString(">>")
+	}
+	if n.Aliased != "" {
+		newline(buf, depth+1)
+		buf.WriteString("= ")
+		buf.WriteString(entities.Replace(n.Aliased))
+	}
+	for _, t := range n.Terms {
+		newline(buf, depth+1)
+		buf.WriteString(entities.Replace(t))
+	}
+	for _, c := range n.Constants {
+		writeConstant(buf, c, depth+1)
+	}
+	for _, f := range n.Fields {
+		writeField(buf, f, depth+1)
+	}
+	for _, m := range n.Methods {
+		writeMethod(buf, m, depth+1)
+	}
+	newline(buf, depth)
+	buf.WriteString("}")
+
+	if n.Color != "" {
+		fill = n.Color
+	}
+	if fill != "" {
+		newline(buf, depth)
+		buf.WriteString("style ")
+		buf.WriteString(n.ID)
+		buf.WriteString(" fill:")
+		buf.WriteString(fill)
+	}
+}
+
+func writeConstant(buf *bufio.Writer, c *model.Constant, depth int) {
+	newline(buf, depth)
+	buf.WriteString(c.Name)
+	buf.WriteString(" = ")
+	buf.WriteString(entities.Replace(c.Value))
+	writeDoc(buf, c.Doc)
+}
+
+// writeDoc trails a member with its comment.
+func writeDoc(buf *bufio.Writer, doc string) {
+	if doc == "" {
+		return
+	}
+	buf.WriteString(" // ")
+	buf.WriteString(entities.Replace(doc))
+}
+
+func writeField(buf *bufio.Writer, f *model.Field, depth int) {
+	newline(buf, depth)
+	buf.WriteString(exportedIcon(f.Exported))
+	buf.WriteString(f.Name)
+	buf.WriteString(" ")
+	buf.WriteString(entities.Replace(f.Type))
+	if f.Tag != "" {
+		buf.WriteString(" `")
+		buf.WriteString(entities.Replace(f.Tag))
+		buf.WriteString("`")
+	}
+	writeDoc(buf, f.Doc)
+}
+
+func writeMethod(buf *bufio.Writer, m *model.Method, depth int) {
+	newline(buf, depth)
+	buf.WriteString(exportedIcon(m.Exported))
+	// Name, qualified by the embedded type it is promoted from
+	if m.PromotedFrom != "" {
+		buf.WriteString(entities.Replace(m.PromotedFrom))
+		buf.WriteString(".")
+	}
+	buf.WriteString(m.Name)
+
+	// parameters
+	buf.WriteString("(")
+	for i, p := range m.Params {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		if p.Name != "" {
+			buf.WriteString(p.Name)
+			buf.WriteString(" ")
+		}
+		buf.WriteString(entities.Replace(p.Type))
+	}
+	buf.WriteString(")")
+
+	// results: Mermaid shows everything after the parameters as the return type
+	if len(m.Results) > 0 {
+		buf.WriteString(" ")
+	}
+	if len(m.Results) > 1 {
+		buf.WriteString("(")
+	}
+	for i, p := range m.Results {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(entities.Replace(p.Type))
+	}
+	if len(m.Results) > 1 {
+		buf.WriteString(")")
+	}
+	if m.Static {
+		buf.WriteString("$")
+	}
+	writeDoc(buf, m.Doc)
+}
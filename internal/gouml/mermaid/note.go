@@ -0,0 +1,26 @@
+package mermaid
+
+import (
+	"bufio"
+
+	"github.com/kazukousen/gouml/model"
+)
+
+func writeNote(buf *bufio.Writer, n *model.Note, depth int) {
+	newline(buf, depth)
+	buf.WriteString("note for ")
+	buf.WriteString(n.Node)
+	buf.WriteString(` "`)
+	lines := n.Lines
+	if n.Member != "" {
+		// the note is about a member, not about the class
+		lines = append([]string{n.Title}, lines...)
+	}
+	for i, line := range lines {
+		if i > 0 {
+			buf.WriteString(`\n`)
+		}
+		buf.WriteString(entities.Replace(line))
+	}
+	buf.WriteString(`"`)
+}
@@ -0,0 +1,33 @@
+package mermaid
+
+import (
+	"io"
+
+	"github.com/kazukousen/gouml/internal/gouml/writer"
+	"github.com/kazukousen/gouml/model"
+)
+
+// Renderer writes a model.Graph as a Mermaid classDiagram.
+type Renderer struct{}
+
+// NewRenderer ...
+func NewRenderer() Renderer {
+	return Renderer{}
+}
+
+// Render ...
+func (r Renderer) Render(w io.Writer, g *model.Graph) (int64, error) {
+	out := writer.New(w)
+	buf := out.Writer
+	for _, n := range g.Nodes {
+		writeNode(buf, n, 1)
+	}
+	for _, e := range g.Edges {
+		writeEdge(buf, g, e, 1)
+	}
+	for _, n := range g.Notes {
+		writeNote(buf, n, 1)
+	}
+	newline(buf, 0)
+	return out.Flush()
+}
@@ -0,0 +1,76 @@
+package mermaid_test
+
+import (
+	"bytes"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+
+	"github.com/kazukousen/gouml/internal/gouml/mermaid"
+	"github.com/kazukousen/gouml/model"
+)
+
+func TestRenderer(t *testing.T) {
+	fset := token.NewFileSet()
+	src := `
+	package shop
+	type Item struct {
+		Name string
+	}
+	type Items []Item
+	type Repository interface {
+		Find(name string) (*Item, error)
+	}
+	type repository struct {
+		items Items
+	}
+	func (r *repository) Find(name string) (*Item, error) { return nil, nil }
+	`
+	want := `
+	class shop_dItem["shop.Item"] {
+		<<ValueObject>>
+		+Name string
+	}
+	style shop_dItem fill:#DA70D6
+	class shop_dItems["shop.Items"] {
+		<<ValueObject>>
+	}
+	style shop_dItems fill:#DA70D6
+	class shop_dRepository["shop.Repository"] {
+		<<interface>>
+		+Find(name string) (*shop.Item, error)
+	}
+	class shop_drepository["shop.repository"] {
+		<<ValueObject>>
+		-items shop.Items
+		+Find(name string) (*shop.Item, error)
+	}
+	style shop_drepository fill:#DA70D6
+	shop_dItems *-- "*" shop_dItem
+	shop_dRepository ..> shop_dItem : return
+	shop_drepository --> "1" shop_dItems
+	shop_drepository ..> shop_dItem : return
+	shop_drepository ..|> shop_dRepository
+`
+	file, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		t.Errorf(": %+v", err)
+		return
+	}
+	conf := types.Config{
+		Importer: importer.Default(),
+	}
+	pkg, err := conf.Check(file.Name.Name, fset, []*ast.File{file}, nil)
+	if err != nil {
+		t.Errorf(": %+v", err)
+		return
+	}
+	buf := &bytes.Buffer{}
+	mermaid.NewRenderer().Render(buf, model.Build([]*types.Package{pkg}, model.Options{}))
+	if g, w := trim(buf.String()), trim(want); g != w {
+		t.Errorf("not equal\ngot: %s\nwant: %s", g, w)
+	}
+}
@@ -0,0 +1,32 @@
+package mermaid
+
+import (
+	"bufio"
+	"strings"
+)
+
+func newline(dst *bufio.Writer, depth int) {
+	dst.WriteString("\n")
+	for i := 0; i < depth; i++ {
+		dst.WriteString("\t")
+	}
+}
+
+func exportedIcon(exported bool) string {
+	if exported {
+		return "+"
+	}
+	return "-"
+}
+
+// entities escapes the characters Mermaid would otherwise read as syntax
+// (e.g. the braces of `interface{}` closing a class body, or the tilde of
+// `~int` marking a generic).
+var entities = strings.NewReplacer(
+	"{", "#123;",
+	"}", "#125;",
+	"<", "#60;",
+	">", "#62;",
+	`"`, "#quot;",
+	"~", "#126;",
+)
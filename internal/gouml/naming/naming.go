@@ -0,0 +1,62 @@
+// Package naming provides the identifiers and labels shared by every diagram backend.
+package naming
+
+import (
+	"fmt"
+	"go/types"
+	"strings"
+)
+
+// ObjID returns the identifier of a package-level object in the diagram.
+// It is derived from the full import path and the name of the object, so that
+// `foo/v2/model.User` and `bar/model.User` never share an identifier.
+func ObjID(obj types.Object) string {
+	if obj.Pkg() == nil {
+		// predeclared, e.g. error
+		return EscapeID(obj.Name())
+	}
+	return EscapeID(obj.Pkg().Path() + "." + obj.Name())
+}
+
+// NamedID ...
+func NamedID(named *types.Named) string {
+	return ObjID(named.Obj())
+}
+
+// UtilityID returns the identifier of the node gathering the package-level
+// objects of a kind, e.g. the functions, of the package at path.
+// It never collides with an ObjID as '#' is not allowed in a Go identifier.
+func UtilityID(path, kind string) string {
+	return EscapeID(path + "#" + kind)
+}
+
+// EscapeID maps a qualified name to a diagram identifier one-to-one.
+// Every character that is not a letter or a digit is replaced with an escape
+// sequence starting with an underscore.
+func EscapeID(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == '_':
+			b.WriteString("__")
+		case r == '.':
+			b.WriteString("_d")
+		case r == '/':
+			b.WriteString("_s")
+		case r == '-':
+			b.WriteString("_h")
+		default:
+			fmt.Fprintf(&b, "_x%04X", r)
+		}
+	}
+	return b.String()
+}
+
+// TypeLabel returns the readable name of a type, qualified by package names only.
+func TypeLabel(typ types.Type) string {
+	return types.TypeString(typ, func(pkg *types.Package) string {
+		return pkg.Name()
+	})
+}
@@ -0,0 +1,25 @@
+package naming_test
+
+import (
+	"testing"
+
+	"github.com/kazukousen/gouml/internal/gouml/naming"
+)
+
+func TestEscapeID(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		want string
+	}{
+		{name: "time.Weekday", want: "time_dWeekday"},
+		{name: "foo/v2/model.User", want: "foo_sv2_smodel_dUser"},
+		{name: "bar/model.User", want: "bar_smodel_dUser"},
+		{name: "example.com/a-b/c_d.T", want: "example_dcom_sa_hb_sc__d_dT"},
+		{name: "example.com/a/b.T", want: "example_dcom_sa_sb_dT"},
+		{name: "example/com/a/b.T", want: "example_scom_sa_sb_dT"},
+	} {
+		if got := naming.EscapeID(tt.name); got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package plantuml
+
+import (
+	"io"
+	"strconv"
+
+	"github.com/kazukousen/gouml/internal/gouml/writer"
+	"github.com/kazukousen/gouml/model"
+)
+
+// DepsRenderer writes the packages of a model.Graph and the imports between
+// them as a PlantUML package diagram.
+type DepsRenderer struct {
+	external bool
+	contract *model.Contract
+	link     func(*model.Package) string
+}
+
+// NewDepsRenderer returns a DepsRenderer, which also draws the imported
+// modules with external, and the imports breaking contract in red unless it
+// is nil.
+func NewDepsRenderer(external bool, contract *model.Contract) DepsRenderer {
+	return DepsRenderer{external: external, contract: contract}
+}
+
+// NewIndexRenderer returns a DepsRenderer linking each package to the
+// location given by link.
+func NewIndexRenderer(link func(*model.Package) string) DepsRenderer {
+	return DepsRenderer{link: link}
+}
+
+// Render ...
+func (r DepsRenderer) Render(w io.Writer, g *model.Graph) (int64, error) {
+	out := writer.New(w)
+	buf := out.Writer
+	d := model.BuildDeps(g, r.external)
+	links := map[string]string{}
+	if r.link != nil {
+		for _, pkg := range g.Packages {
+			if len(g.NodesOf(pkg)) > 0 {
+				links[pkg.Path] = r.link(pkg)
+			}
+		}
+	}
+	if r.contract != nil {
+		d.Mark(r.contract.Violations(g))
+	}
+	for _, p := range d.Packages {
+		newline(buf, 0)
+		buf.WriteString(`package "`)
+		buf.WriteString(p.Path)
+		if !p.Module {
+			buf.WriteString(`\n`)
+			buf.WriteString(strconv.Itoa(p.Types))
+			if p.Types == 1 {
+				buf.WriteString(" type")
+			} else {
+				buf.WriteString(" types")
+			}
+		}
+		buf.WriteString(`" as `)
+		buf.WriteString(p.ID)
+		if p.Module {
+			buf.WriteString(" <<module>>")
+		} else if link := links[p.Path]; link != "" {
+			buf.WriteString(" [[")
+			buf.WriteString(link)
+			buf.WriteString("]]")
+		}
+		buf.WriteString(" {")
+		newline(buf, 0)
+		buf.WriteString("}")
+	}
+
+	newline(buf, 0)
+	for _, dep := range d.Dependencies {
+		newline(buf, 0)
+		buf.WriteString(dep.From)
+		if dep.Violation {
+			buf.WriteString(" -[#red]-> ")
+		} else {
+			buf.WriteString(" --> ")
+		}
+		buf.WriteString(dep.To)
+		if dep.Types > 0 {
+			buf.WriteString(" : ")
+			buf.WriteString(strconv.Itoa(dep.Types))
+		}
+	}
+	newline(buf, 0)
+	newline(buf, 0)
+	return out.Flush()
+}
@@ -0,0 +1,43 @@
+package plantuml
+
+import (
+	"bufio"
+
+	"github.com/kazukousen/gouml/model"
+)
+
+var edgeArrows = map[model.EdgeKind]string{
+	model.EdgeField:        " --> ",
+	model.EdgeUse:          " ..> ",
+	model.EdgeReturn:       " ..> ",
+	model.EdgeWrap:         " *-- ",
+	model.EdgeImplements:   " -up-|> ",
+	model.EdgeEmbed:        " *-up- ",
+	model.EdgeExtend:       " -up-|> ",
+	model.EdgeAlias:        " ..> ",
+	model.EdgeConstraint:   " ..> ",
+	model.EdgeTypeArgument: " ..> ",
+}
+
+var edgeLabels = map[model.EdgeKind]string{
+	model.EdgeUse:          " : <<use>>",
+	model.EdgeReturn:       " : <<return>>",
+	model.EdgeEmbed:        " : <<embed>>",
+	model.EdgeExtend:       " : <<embed>>",
+	model.EdgeAlias:        " : <<alias>>",
+	model.EdgeConstraint:   " : <<constraint>>",
+	model.EdgeTypeArgument: " : <<bind>>",
+}
+
+func writeEdge(buf *bufio.Writer, e *model.Edge, depth int) {
+	newline(buf, depth)
+	buf.WriteString(e.From)
+	buf.WriteString(edgeArrows[e.Kind])
+	if e.Multiplicity != "" {
+		buf.WriteString(`"`)
+		buf.WriteString(string(e.Multiplicity))
+		buf.WriteString(`" `)
+	}
+	buf.WriteString(e.To)
+	buf.WriteString(edgeLabels[e.Kind])
+}
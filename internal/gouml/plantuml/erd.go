@@ -0,0 +1,86 @@
+package plantuml
+
+import (
+	"bufio"
+	"io"
+
+	"github.com/kazukousen/gouml/internal/gouml/writer"
+	"github.com/kazukousen/gouml/model"
+)
+
+// ERDRenderer writes the tables of a model.Graph as a PlantUML
+// entity-relationship diagram.
+type ERDRenderer struct{}
+
+// NewERDRenderer ...
+func NewERDRenderer() ERDRenderer {
+	return ERDRenderer{}
+}
+
+// Render ...
+func (r ERDRenderer) Render(w io.Writer, g *model.Graph) (int64, error) {
+	out := writer.New(w)
+	buf := out.Writer
+	e := model.BuildERD(g)
+	for _, t := range e.Tables {
+		writeTable(buf, t, 0)
+	}
+
+	newline(buf, 0)
+	for _, rel := range e.Relations {
+		newline(buf, 0)
+		buf.WriteString(rel.From)
+		buf.WriteString(" }o--|| ")
+		buf.WriteString(rel.To)
+		buf.WriteString(" : ")
+		buf.WriteString(rel.Column)
+	}
+	newline(buf, 0)
+	newline(buf, 0)
+	return out.Flush()
+}
+
+func writeTable(buf *bufio.Writer, t *model.Table, depth int) {
+	newline(buf, depth)
+	buf.WriteString(`entity "`)
+	buf.WriteString(t.Name)
+	buf.WriteString(`" as `)
+	buf.WriteString(t.ID)
+	buf.WriteString(" {")
+	// primary keys are listed above the line
+	keys := 0
+	for _, c := range t.Columns {
+		if c.PrimaryKey {
+			writeColumn(buf, c, depth+1)
+			keys++
+		}
+	}
+	if keys > 0 && keys < len(t.Columns) {
+		newline(buf, depth+1)
+		buf.WriteString("--")
+	}
+	for _, c := range t.Columns {
+		if !c.PrimaryKey {
+			writeColumn(buf, c, depth+1)
+		}
+	}
+	newline(buf, depth)
+	buf.WriteString("}")
+}
+
+func writeColumn(buf *bufio.Writer, c *model.Column, depth int) {
+	newline(buf, depth)
+	if !c.Nullable {
+		// mandatory
+		buf.WriteString("*")
+	}
+	buf.WriteString(c.Name)
+	buf.WriteString(" : ")
+	buf.WriteString(c.Type)
+	if c.PrimaryKey {
+		buf.WriteString(" <<PK>>")
+	}
+	if c.ForeignKey != "" {
+		buf.WriteString(" <<FK>>")
+	}
+}
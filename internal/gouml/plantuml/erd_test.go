@@ -0,0 +1,45 @@
+package plantuml_test
+
+import (
+	"bytes"
+	"go/types"
+	"testing"
+
+	"github.com/kazukousen/gouml/internal/gouml/plantuml"
+	"github.com/kazukousen/gouml/model"
+)
+
+func TestERDRenderer(t *testing.T) {
+	src := `
+	package shop
+	type Customer struct {
+		ID   int64   ` + "`db:\"id\"`" + `
+		Name *string ` + "`db:\"name\"`" + `
+	}
+	type Order struct {
+		ID         int64 ` + "`db:\"id\"`" + `
+		CustomerID int64 ` + "`db:\"customer_id\"`" + `
+	}
+	`
+	want := `
+	entity "customer" as shop_dCustomer {
+		*id : int64 <<PK>>
+		--
+		name : *string
+	}
+	entity "order" as shop_dOrder {
+		*id : int64 <<PK>>
+		--
+		*customer_id : int64 <<FK>>
+	}
+
+	shop_dOrder }o--|| shop_dCustomer : customer_id
+
+`
+	pkg := check(t, src)
+	buf := &bytes.Buffer{}
+	plantuml.NewERDRenderer().Render(buf, model.Build([]*types.Package{pkg}, model.Options{}))
+	if g, w := trim(buf.String()), trim(want); g != w {
+		t.Errorf("not equal\ngot: %s\nwant: %s", g, w)
+	}
+}
@@ -1,3 +0,0 @@
-package plantuml
-
-var ExportTestNotesAppend = (Notes).append
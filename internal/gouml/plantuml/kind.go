@@ -2,7 +2,8 @@ package plantuml
 
 import (
 	"fmt"
-	"go/types"
+
+	"github.com/kazukousen/gouml/model"
 )
 
 type modelKind string
@@ -11,27 +12,61 @@ const (
 	modelKindInterface   modelKind = `interface "%s" as %s`
 	modelKindValueObject modelKind = `class "%s" as %s <<V,Orchid>>`
 	modelKindEntity      modelKind = `class "%s" as %s <<E,#FFCC00>>`
+	modelKindClass       modelKind = `class "%s" as %s`
+	modelKindConstraint  modelKind = `interface "%s" as %s <<constraint>>`
+	modelKindAlias       modelKind = `class "%s" as %s <<alias>>`
+	modelKindUtility     modelKind = `class "%s" as %s <<utility>>`
+	modelKindGlobals     modelKind = `class "%s" as %s <<globals>>`
+	modelKindEnum        modelKind = `enum "%s" as %s`
 )
 
+var modelKinds = map[model.Kind]modelKind{
+	model.KindInterface:   modelKindInterface,
+	model.KindValueObject: modelKindValueObject,
+	model.KindEntity:      modelKindEntity,
+	model.KindClass:       modelKindClass,
+	model.KindConstraint:  modelKindConstraint,
+	model.KindAlias:       modelKindAlias,
+	model.KindUtility:     modelKindUtility,
+	model.KindGlobals:     modelKindGlobals,
+	model.KindEnum:        modelKindEnum,
+}
+
 func (k modelKind) Printf(name, alias string) string {
 	return fmt.Sprintf(string(k), name, alias)
 }
 
-func isCommand(f *types.Func) bool {
-	// *types.Func.Type() is always a *types.Signature
-	sig := f.Type().(*types.Signature)
-	if _, ok := sig.Recv().Type().(*types.Pointer); !ok {
-		return false
+// roles are the stereotypes of the roles, with a spot of their own.
+var roles = map[model.Role]string{
+	model.RoleRepository: "<<(R,#7FB3D5) repository>>",
+	model.RoleService:    "<<(S,#82E0AA) service>>",
+	model.RoleFactory:    "<<(F,#F8C471) factory>>",
+}
+
+// header returns the declaration of n, with the stereotype and the color
+// given by directives, or else the stereotype of its role.
+func header(n *model.Node) string {
+	h := modelKinds[n.Kind].Printf(n.Title(), n.ID)
+	if n.Stereotype != "" {
+		h = fmt.Sprintf(`%s "%s" as %s <<%s>>`, keyword(n), n.Title(), n.ID, n.Stereotype)
+	} else if r, ok := roles[n.Role]; ok {
+		h = fmt.Sprintf(`%s "%s" as %s %s`, keyword(n), n.Title(), n.ID, r)
+	}
+	if n.Link != "" {
+		h += " [[" + n.Link + "]]"
 	}
-	if sig.Results().Len() == 0 {
-		return true
+	if n.Color != "" {
+		h += " " + n.Color
 	}
-	if sig.Results().Len() == 1 {
-		t := sig.Results().At(0).Type()
-		errType := types.Universe.Lookup("error").Type()
-		if types.Implements(t, errType.Underlying().(*types.Interface)) {
-			return true
-		}
+	return h
+}
+
+func keyword(n *model.Node) string {
+	switch n.Kind {
+	case model.KindInterface, model.KindConstraint:
+		return "interface"
+	case model.KindEnum:
+		return "enum"
 	}
-	return false
+	return "class"
 }
@@ -1,5 +1,14 @@
 package plantuml_test
 
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+)
+
 func trim(src string) string {
 	dst := make([]byte, 0, len(src))
 	for _, ch := range src {
@@ -10,3 +19,20 @@ func trim(src string) string {
 	}
 	return string(dst)
 }
+
+func check(t *testing.T, src string) *types.Package {
+	t.Helper()
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		t.Fatalf(": %+v", err)
+	}
+	conf := types.Config{
+		Importer: importer.Default(),
+	}
+	pkg, err := conf.Check(file.Name.Name, fset, []*ast.File{file}, nil)
+	if err != nil {
+		t.Fatalf(": %+v", err)
+	}
+	return pkg
+}
@@ -0,0 +1,121 @@
+package plantuml
+
+import (
+	"bufio"
+
+	"github.com/kazukousen/gouml/model"
+)
+
+func writeNode(buf *bufio.Writer, n *model.Node, depth int) {
+	newline(buf, depth)
+	buf.WriteString(header(n))
+	if len(n.Fields) == 0 && len(n.Methods) == 0 && len(n.Terms) == 0 && len(n.Constants) == 0 && n.Aliased == "" {
+		return
+	}
+	buf.WriteString(` {`)
+	if n.Aliased != "" {
+		newline(buf, depth+1)
+		buf.WriteString("= ")
+		buf.WriteString(n.Aliased)
+	}
+	for _, t := range n.Terms {
+		newline(buf, depth+1)
+		buf.WriteString(t)
+	}
+	for _, c := range n.Constants {
+		writeConstant(buf, c, depth+1)
+	}
+	for _, f := range n.Fields {
+		writeField(buf, f, depth+1)
+	}
+	from := ""
+	for _, m := range n.Methods {
+		if m.PromotedFrom != from && m.PromotedFrom != "" {
+			// separates the methods promoted from each embedded type
+			newline(buf, depth+1)
+			buf.WriteString(".. ")
+			buf.WriteString(m.PromotedFrom)
+			buf.WriteString(" ..")
+		}
+		from = m.PromotedFrom
+		writeMethod(buf, m, depth+1)
+	}
+	newline(buf, depth)
+	buf.WriteString("}")
+}
+
+func writeConstant(buf *bufio.Writer, c *model.Constant, depth int) {
+	newline(buf, depth)
+	buf.WriteString(c.Name)
+	buf.WriteString(" = ")
+	buf.WriteString(c.Value)
+	writeDoc(buf, c.Doc)
+}
+
+// writeDoc trails a member with its comment.
+func writeDoc(buf *bufio.Writer, doc string) {
+	if doc == "" {
+		return
+	}
+	buf.WriteString(" <color:gray>")
+	buf.WriteString(doc)
+	buf.WriteString("</color>")
+}
+
+func writeField(buf *bufio.Writer, f *model.Field, depth int) {
+	newline(buf, depth)
+	buf.WriteString(exportedIcon(f.Exported))
+	buf.WriteString(f.Name)
+	buf.WriteString(": ")
+	buf.WriteString(f.Type)
+	if f.Tag != "" {
+		buf.WriteString(" `")
+		buf.WriteString(f.Tag)
+		buf.WriteString("`")
+	}
+	writeDoc(buf, f.Doc)
+}
+
+func writeMethod(buf *bufio.Writer, m *model.Method, depth int) {
+	newline(buf, depth)
+	if m.Static {
+		buf.WriteString("{static} ")
+	}
+	buf.WriteString(exportedIcon(m.Exported))
+	// Name
+	buf.WriteString(m.Name)
+
+	// parameters
+	buf.WriteString("(")
+	for i, p := range m.Params {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(p.Name)
+		buf.WriteString(": ")
+		buf.WriteString(p.Type)
+	}
+	buf.WriteString(")")
+
+	// results
+	if len(m.Results) > 0 {
+		buf.WriteString(": ")
+	}
+	if len(m.Results) > 1 {
+		buf.WriteString("(")
+	}
+	for i, p := range m.Results {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		if p.Name != "" {
+			buf.WriteString(p.Name)
+			buf.WriteString(": ")
+		}
+		buf.WriteString(p.Type)
+	}
+	if len(m.Results) > 1 {
+		buf.WriteString(")")
+	}
+	writeDoc(buf, m.Doc)
+}
@@ -1,69 +1,43 @@
 package plantuml
 
 import (
-	"bytes"
-	"go/types"
-	"strings"
-)
+	"bufio"
 
-// Notes ...
-type Notes map[*types.Named]Note
+	"github.com/kazukousen/gouml/internal/gouml/naming"
+	"github.com/kazukousen/gouml/model"
+)
 
-func (ns Notes) append(k *types.Named, c *types.Const) {
-	note, ok := ns[k]
-	if !ok {
-		note = []*types.Const{}
+func noteID(n *model.Note) string {
+	switch n.Kind {
+	case model.NoteDoc:
+		return "D_" + n.Node
+	case model.NoteText:
+		return "T_" + n.Node
 	}
-	note = append(note, c)
-	ns[k] = note
-}
-
-// Note ...
-type Note []*types.Const
-
-// WriteTo ...
-func (ns Notes) WriteTo(buf *bytes.Buffer) {
-	newline(buf, 0)
-	for named, n := range ns {
-		to := extractName(named.String())
-		from := "N_" + strings.Replace(to, ".", "_", -1)
-
-		newline(buf, 0)
-		buf.WriteString(`package "`)
-		buf.WriteString(named.Obj().Pkg().Name())
-		buf.WriteString(`" {`)
-
-		// write header
-		newline(buf, 1)
-		buf.WriteString("note as ")
-		buf.WriteString(from)
-		// write title
-		newline(buf, 2)
-		buf.WriteString("<b>")
-		buf.WriteString(extractTypeName(to))
-		buf.WriteString("</b>\n")
-
-		// write elements
-		n.WriteTo(buf, 2)
-		// write footer
-		newline(buf, 1)
-		buf.WriteString("end note")
-
-		newline(buf, 0)
-		buf.WriteString(`}`)
-
-		// write relation
-		newline(buf, 0)
-		buf.WriteString(from)
-		buf.WriteString(" --> ")
-		buf.WriteString(to)
+	if n.Member != "" {
+		// a node may have a note for each of its members
+		return "N_" + n.Node + "_m" + naming.EscapeID(n.Member)
 	}
+	return "N_" + n.Node
 }
 
-// WriteTo ...
-func (n Note) WriteTo(buf *bytes.Buffer, depth int) {
-	for _, row := range n {
-		newline(buf, depth)
-		buf.WriteString(row.Name())
+func writeNote(buf *bufio.Writer, n *model.Note, depth int) {
+	// write header
+	newline(buf, depth)
+	buf.WriteString("note as ")
+	buf.WriteString(noteID(n))
+	// write title
+	newline(buf, depth+1)
+	buf.WriteString("<b>")
+	buf.WriteString(n.Title)
+	buf.WriteString("</b>\n")
+
+	// write elements
+	for _, line := range n.Lines {
+		newline(buf, depth+1)
+		buf.WriteString(line)
 	}
+	// write footer
+	newline(buf, depth)
+	buf.WriteString("end note")
 }
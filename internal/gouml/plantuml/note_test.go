@@ -2,19 +2,14 @@ package plantuml_test
 
 import (
 	"bytes"
-	"go/ast"
-	"go/importer"
-	"go/parser"
-	"go/token"
 	"go/types"
 	"testing"
 
 	"github.com/kazukousen/gouml/internal/gouml/plantuml"
+	"github.com/kazukousen/gouml/model"
 )
 
 func TestNote(t *testing.T) {
-	notes := plantuml.Notes{}
-	fset := token.NewFileSet()
 	src := `
 	package time
 	type Weekday int
@@ -29,10 +24,11 @@ func TestNote(t *testing.T) {
 		Saturday
 	)
 	`
-	want := `
+	want := `set namespaceSeparator none
 
 	package "time" {
-		note as N_time_Weekday
+		class "Weekday" as time_dWeekday <<V,Orchid>>
+		note as N_time_dWeekday
 		<b>Weekday</b>
 
 		Friday
@@ -42,32 +38,48 @@ func TestNote(t *testing.T) {
 		Thursday
 		Tuesday
 		Wednesday
-	end note
+		end note
 	}
-	N_time_Weekday --> time.Weekday`
-	file, err := parser.ParseFile(fset, "", src, parser.ParseComments)
-	if err != nil {
-		t.Errorf(": %+v", err)
-		return
-	}
-	conf := types.Config{
-		Importer: importer.Default(),
-	}
-	pkg, err := conf.Check(file.Name.Name, fset, []*ast.File{file}, nil)
-	if err != nil {
-		t.Errorf(": %+v", err)
-		return
+
+	N_time_dWeekday --> time_dWeekday
+
+`
+	pkg := check(t, src)
+	buf := &bytes.Buffer{}
+	plantuml.NewRenderer().Render(buf, model.Build([]*types.Package{pkg}, model.Options{ConstantNotes: true}))
+	if g, w := trim(buf.String()), trim(want); g != w {
+		t.Errorf("not equal\ngot: %s\nwant: %s", g, w)
 	}
-	for _, name := range pkg.Scope().Names() {
-		obj := pkg.Scope().Lookup(name)
-		if c, _ := obj.(*types.Const); c != nil {
-			if named, _ := obj.Type().(*types.Named); named != nil {
-				plantuml.ExportTestNotesAppend(notes, named, c)
-			}
+}
+
+func TestEnum(t *testing.T) {
+	src := `
+	package fs
+	type Mode uint8
+
+	const (
+		Read Mode = 1 << iota
+		Write
+		Exec
+	)
+	func (m Mode) String() string { return "" }
+	`
+	want := `set namespaceSeparator none
+
+	package "fs" {
+		enum "Mode" as fs_dMode {
+			Read = 1
+			Write = 2
+			Exec = 4
+			+String(): string
 		}
 	}
+
+
+`
+	pkg := check(t, src)
 	buf := &bytes.Buffer{}
-	notes.WriteTo(buf)
+	plantuml.NewRenderer().Render(buf, model.Build([]*types.Package{pkg}, model.Options{}))
 	if g, w := trim(buf.String()), trim(want); g != w {
 		t.Errorf("not equal\ngot: %s\nwant: %s", g, w)
 	}
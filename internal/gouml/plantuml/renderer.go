@@ -0,0 +1,69 @@
+package plantuml
+
+import (
+	"io"
+
+	"github.com/kazukousen/gouml/internal/gouml/writer"
+	"github.com/kazukousen/gouml/model"
+)
+
+// Renderer writes a model.Graph as PlantUML.
+type Renderer struct{}
+
+// NewRenderer ...
+func NewRenderer() Renderer {
+	return Renderer{}
+}
+
+// Render ...
+func (r Renderer) Render(w io.Writer, g *model.Graph) (int64, error) {
+	out := writer.New(w)
+	buf := out.Writer
+	// package blocks are named after import paths, which contain dots
+	buf.WriteString("set namespaceSeparator none\n")
+
+	for _, pkg := range g.Packages {
+		nodes, notes := g.NodesOf(pkg), g.NotesOf(pkg)
+		if len(nodes) == 0 && len(notes) == 0 {
+			continue
+		}
+		newline(buf, 0)
+		buf.WriteString(`package "`)
+		buf.WriteString(pkg.Path)
+		buf.WriteString(`" {`)
+		rest, layers := model.ByLayer(nodes)
+		for _, n := range rest {
+			writeNode(buf, n, 1)
+		}
+		for _, l := range layers {
+			newline(buf, 1)
+			buf.WriteString(`frame "`)
+			buf.WriteString(l.Name)
+			buf.WriteString(`" {`)
+			for _, n := range l.Nodes {
+				writeNode(buf, n, 2)
+			}
+			newline(buf, 1)
+			buf.WriteString("}")
+		}
+		for _, n := range notes {
+			writeNote(buf, n, 1)
+		}
+		newline(buf, 0)
+		buf.WriteString("}")
+	}
+
+	newline(buf, 0)
+	for _, e := range g.Edges {
+		writeEdge(buf, e, 0)
+	}
+	for _, n := range g.Notes {
+		newline(buf, 0)
+		buf.WriteString(noteID(n))
+		buf.WriteString(" --> ")
+		buf.WriteString(n.Node)
+	}
+	newline(buf, 0)
+	newline(buf, 0)
+	return out.Flush()
+}
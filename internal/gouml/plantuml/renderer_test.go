@@ -0,0 +1,192 @@
+package plantuml_test
+
+import (
+	"bytes"
+	"go/types"
+	"testing"
+
+	"github.com/kazukousen/gouml/internal/gouml/plantuml"
+	"github.com/kazukousen/gouml/model"
+)
+
+func TestRenderer(t *testing.T) {
+	src := `
+	package shop
+	type Item struct {
+		Name string
+	}
+	type Items []Item
+	type Finder interface {
+		Find(name string) (*Item, error)
+	}
+	type repository struct {
+		items Items
+		last  *Item
+	}
+	func (r *repository) Find(name string) (*Item, error) { return nil, nil }
+	`
+	want := `set namespaceSeparator none
+
+	package "shop" {
+		interface "Finder" as shop_dFinder {
+			+Find(name: string): (*shop.Item, error)
+		}
+		class "Item" as shop_dItem <<V,Orchid>> {
+			+Name: string
+		}
+		class "Items" as shop_dItems <<V,Orchid>>
+		class "repository" as shop_drepository <<V,Orchid>> {
+			-items: shop.Items
+			-last: *shop.Item
+			+Find(name: string): (*shop.Item, error)
+		}
+	}
+
+	shop_dFinder ..> shop_dItem : <<return>>
+	shop_dItems *-- "*" shop_dItem
+	shop_drepository --> "1" shop_dItems
+	shop_drepository --> "0..1" shop_dItem
+	shop_drepository ..> shop_dItem : <<return>>
+	shop_drepository -up-|> shop_dFinder
+
+`
+	pkg := check(t, src)
+	buf := &bytes.Buffer{}
+	plantuml.NewRenderer().Render(buf, model.Build([]*types.Package{pkg}, model.Options{}))
+	if g, w := trim(buf.String()), trim(want); g != w {
+		t.Errorf("not equal\ngot: %s\nwant: %s", g, w)
+	}
+}
+
+func TestRendererEmbedding(t *testing.T) {
+	src := `
+	package io
+	type Reader interface {
+		Read(p []byte) (n int, err error)
+	}
+	type ReadCloser interface {
+		Reader
+		Close() error
+	}
+	type File struct {
+		Reader
+	}
+	func Open() *File { return nil }
+	`
+	want := `set namespaceSeparator none
+
+	package "io" {
+		class "File" as io_dFile <<V,Orchid>> {
+			{static} +Open(): *io.File
+			.. io.Reader ..
+			+Read(p: []byte): (n: int, err: error)
+		}
+		interface "ReadCloser" as io_dReadCloser {
+			+Close(): error
+			.. io.Reader ..
+			+Read(p: []byte): (n: int, err: error)
+		}
+		interface "Reader" as io_dReader {
+			+Read(p: []byte): (n: int, err: error)
+		}
+	}
+
+	io_dFile *-up- io_dReader : <<embed>>
+	io_dReadCloser -up-|> io_dReader : <<embed>>
+
+`
+	pkg := check(t, src)
+	buf := &bytes.Buffer{}
+	plantuml.NewRenderer().Render(buf, model.Build([]*types.Package{pkg}, model.Options{}))
+	if g, w := trim(buf.String()), trim(want); g != w {
+		t.Errorf("not equal\ngot: %s\nwant: %s", g, w)
+	}
+}
+
+func TestRendererFunctions(t *testing.T) {
+	src := `
+	package app
+	type Server struct{}
+	func NewServer(addr string) *Server { return nil }
+	func Run(s *Server) error { return nil }
+	`
+	want := `set namespaceSeparator none
+
+	package "app" {
+		class "Server" as app_dServer <<V,Orchid>> {
+			{static} +NewServer(addr: string): *app.Server
+		}
+		class "functions" as app_x0023functions <<utility>> {
+			+Run(s: *app.Server): error
+		}
+	}
+
+	app_x0023functions ..> app_dServer : <<use>>
+
+`
+	pkg := check(t, src)
+	buf := &bytes.Buffer{}
+	plantuml.NewRenderer().Render(buf, model.Build([]*types.Package{pkg}, model.Options{}))
+	if g, w := trim(buf.String()), trim(want); g != w {
+		t.Errorf("not equal\ngot: %s\nwant: %s", g, w)
+	}
+}
+
+func TestRendererDirectives(t *testing.T) {
+	g := model.NewGraph()
+	pkg := &model.Package{Path: "order", Name: "order"}
+	g.AddPackage(pkg)
+	g.AddNode(&model.Node{ID: "order_dOrder", Name: "Order", Package: pkg, Kind: model.KindEntity, Stereotype: "aggregate", Color: "#FFAA00"})
+	g.AddNode(&model.Node{ID: "order_dLine", Name: "Line", Package: pkg, Kind: model.KindValueObject, Color: "#EEEEEE"})
+	g.AddNote(&model.Note{Package: pkg, Node: "order_dOrder", Kind: model.NoteText, Title: "Order", Lines: []string{"consistency boundary"}})
+	want := `set namespaceSeparator none
+
+	package "order" {
+		class "Order" as order_dOrder <<aggregate>> #FFAA00
+		class "Line" as order_dLine <<V,Orchid>> #EEEEEE
+		note as T_order_dOrder
+		<b>Order</b>
+
+		consistency boundary
+		end note
+	}
+
+	T_order_dOrder --> order_dOrder
+
+`
+	buf := &bytes.Buffer{}
+	plantuml.NewRenderer().Render(buf, g)
+	if g, w := trim(buf.String()), trim(want); g != w {
+		t.Errorf("not equal\ngot: %s\nwant: %s", g, w)
+	}
+}
+
+func TestRendererRoles(t *testing.T) {
+	g := model.NewGraph()
+	pkg := &model.Package{Path: "user", Name: "user"}
+	g.AddPackage(pkg)
+	g.AddNode(&model.Node{ID: "user_dUser", Name: "User", Package: pkg, Kind: model.KindEntity})
+	g.AddNode(&model.Node{ID: "user_dRepository", Name: "Repository", Package: pkg, Kind: model.KindInterface, Role: model.RoleRepository, Layer: "repositories"})
+	g.AddNode(&model.Node{ID: "user_dSignUp", Name: "SignUp", Package: pkg, Kind: model.KindEntity, Role: model.RoleService, Layer: "services"})
+	g.AddNode(&model.Node{ID: "user_dStore", Name: "Store", Package: pkg, Kind: model.KindValueObject, Role: model.RoleRepository, Layer: "repositories", Stereotype: "adapter"})
+	want := `set namespaceSeparator none
+
+	package "user" {
+		class "User" as user_dUser <<E,#FFCC00>>
+		frame "repositories" {
+			interface "Repository" as user_dRepository <<(R,#7FB3D5) repository>>
+			class "Store" as user_dStore <<adapter>>
+		}
+		frame "services" {
+			class "SignUp" as user_dSignUp <<(S,#82E0AA) service>>
+		}
+	}
+
+
+`
+	buf := &bytes.Buffer{}
+	plantuml.NewRenderer().Render(buf, g)
+	if g, w := trim(buf.String()), trim(want); g != w {
+		t.Errorf("not equal\ngot: %s\nwant: %s", g, w)
+	}
+}
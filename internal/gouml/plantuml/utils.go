@@ -1,11 +1,10 @@
 package plantuml
 
 import (
-	"bytes"
-	"strings"
+	"bufio"
 )
 
-func newline(dst *bytes.Buffer, depth int) {
+func newline(dst *bufio.Writer, depth int) {
 	dst.WriteString("\n")
 	for i := 0; i < depth; i++ {
 		dst.WriteString("\t")
@@ -18,29 +17,3 @@ func exportedIcon(exported bool) string {
 	}
 	return "-"
 }
-
-func extractName(full string) string {
-	if strings.Contains(full, "/") {
-		parts := strings.Split(full, "/")
-		full = parts[len(parts)-1]
-	}
-	return full
-}
-
-func extractPkgName(name string) string {
-	if strings.Contains(name, ".") {
-		parts := strings.Split(name, ".")
-		name = parts[len(parts)-2]
-	}
-	return name
-}
-
-func extractTypeName(name string) string {
-	if strings.Contains(name, ".") {
-		parts := strings.Split(name, ".")
-		name = parts[len(parts)-1]
-	}
-	return name
-}
-
-type exists map[string]struct{}
@@ -0,0 +1,39 @@
+// Package writer buffers the output of the diagram backends.
+package writer
+
+import (
+	"bufio"
+	"io"
+)
+
+// Writer is a bufio.Writer counting the bytes it writes to the underlying
+// io.Writer. Like bufio.Writer, it keeps the first error, so that the
+// backends may ignore the errors until Flush.
+type Writer struct {
+	*bufio.Writer
+	counter *counter
+}
+
+// New returns a Writer writing to w.
+func New(w io.Writer) *Writer {
+	c := &counter{w: w}
+	return &Writer{Writer: bufio.NewWriter(c), counter: c}
+}
+
+// Flush writes the buffered data, and returns the number of bytes written
+// to the underlying io.Writer and the first error.
+func (w *Writer) Flush() (int64, error) {
+	err := w.Writer.Flush()
+	return w.counter.n, err
+}
+
+type counter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *counter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
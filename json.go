@@ -0,0 +1,11 @@
+package gouml
+
+import (
+	"github.com/go-kit/kit/log"
+	"github.com/kazukousen/gouml/internal/gouml/jsonschema"
+)
+
+// JSONParser writes the analysed model as a schema.Document in JSON.
+func JSONParser(logger log.Logger, opts Options) Parser {
+	return NewParser(logger, jsonschema.NewRenderer(), opts)
+}
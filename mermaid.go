@@ -0,0 +1,11 @@
+package gouml
+
+import (
+	"github.com/go-kit/kit/log"
+	"github.com/kazukousen/gouml/internal/gouml/mermaid"
+)
+
+// MermaidParser ...
+func MermaidParser(logger log.Logger, opts Options) Parser {
+	return NewParser(logger, mermaid.NewRenderer(), opts)
+}
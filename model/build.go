@@ -0,0 +1,840 @@
+package model
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/kazukousen/gouml/internal/gouml/naming"
+)
+
+// Options control what Build analyses.
+type Options struct {
+	// HidePromoted leaves out the methods promoted from embedded fields and interfaces.
+	HidePromoted bool
+	// CollapseAliases leaves out the nodes of type aliases. The relations to an
+	// alias are drawn to the aliased type instead.
+	CollapseAliases bool
+	// ConstantNotes lists the constants of a type in a note, in alphabetical
+	// order, instead of making the type an enum.
+	ConstantNotes bool
+	// Tags records the struct tags of the fields.
+	Tags bool
+	// Docs records the comments of the types, fields and methods, and attaches
+	// a note with the doc comment to each type. It needs the syntax.
+	Docs bool
+	// DocLimit cuts the comments of the members longer than this many
+	// characters. Zero means no limit.
+	DocLimit int
+	// Classification gives the kinds and stereotypes of the types.
+	Classification Classification
+	// Roles recognises the repositories, services and factories from their
+	// shape. The stereotypes of the classification take precedence.
+	Roles bool
+	// Layers groups the nodes of each role, and the ones implementing them,
+	// into layers. It implies Roles.
+	Layers bool
+}
+
+// Build analyses the types, constants, functions and variables declared in
+// pkgs into a Graph, without their syntax.
+func Build(pkgs []*types.Package, opts Options) *Graph {
+	return BuildSources(Sources(pkgs), opts)
+}
+
+// BuildSources is Build also analysing the syntax of srcs where it is given.
+func BuildSources(srcs []*Source, opts Options) *Graph {
+	b := &builder{
+		opts:      opts,
+		graph:     NewGraph(),
+		pkgs:      map[*types.Package]*Package{},
+		ex:        map[string]struct{}{},
+		embeds:    map[[2]string]struct{}{},
+		utilities: map[string]*Node{},
+		owners:    map[*types.Func]*Node{},
+		docs:      map[types.Object]string{},
+		dirs:      map[types.Object]*directives{},
+		ifaces:    map[string]*types.Interface{},
+	}
+	b.build(srcs)
+	return b.graph
+}
+
+type builder struct {
+	opts  Options
+	graph *Graph
+	pkgs  map[*types.Package]*Package
+	// ex holds the IDs of the types declared in the loaded packages.
+	// Edges are only drawn to them.
+	ex map[string]struct{}
+	// embeds holds the pairs of IDs connected by an EdgeEmbed or an EdgeExtend,
+	// which need no EdgeImplements in addition.
+	embeds map[[2]string]struct{}
+	// utilities holds the utility and globals nodes by ID.
+	utilities map[string]*Node
+	// owners holds the node each function and method is listed in.
+	owners map[*types.Func]*Node
+	// docs holds the comments of the objects declared in the syntax.
+	docs map[types.Object]string
+	// dirs holds the directives of the objects declared in the syntax.
+	dirs map[types.Object]*directives
+	// ifaces caches the interfaces the rules of the classification refer to.
+	ifaces map[string]*types.Interface
+}
+
+func (b *builder) build(srcs []*Source) {
+	objects := []types.Object{}
+	for _, src := range srcs {
+		pkg := src.Types
+		p := &Package{Path: pkg.Path(), Name: pkg.Name(), Module: src.Modules[pkg.Path()], Imports: []*Import{}}
+		for _, imp := range pkg.Imports() {
+			p.Imports = append(p.Imports, &Import{Path: imp.Path(), Module: src.Modules[imp.Path()]})
+		}
+		sort.Slice(p.Imports, func(i, j int) bool {
+			return p.Imports[i].Path < p.Imports[j].Path
+		})
+		b.pkgs[pkg] = p
+		b.graph.AddPackage(p)
+		b.comments(src)
+
+		scope := pkg.Scope()
+		for _, name := range scope.Names() {
+			obj := scope.Lookup(name)
+			if b.ignored(obj) {
+				continue
+			}
+			objects = append(objects, obj)
+
+			if tn, ok := obj.(*types.TypeName); ok && !b.collapsed(tn) {
+				b.ex[naming.ObjID(obj)] = struct{}{}
+			}
+		}
+	}
+
+	notes := map[*types.Named]*Note{}
+	enums := []*types.Named{}
+	consts := map[*types.Named][]*types.Const{}
+	for _, obj := range objects {
+		switch obj := obj.(type) {
+
+		// declared type
+		case *types.TypeName:
+			if b.collapsed(obj) {
+				continue
+			}
+			b.graph.AddNode(b.node(obj))
+
+		// declared constant
+		case *types.Const:
+			named, _ := types.Unalias(obj.Type()).(*types.Named)
+			if named == nil || b.target(named) == "" {
+				continue
+			}
+			if !b.opts.ConstantNotes {
+				if _, ok := consts[named]; !ok {
+					enums = append(enums, named)
+				}
+				consts[named] = append(consts[named], obj)
+				continue
+			}
+			note, ok := notes[named]
+			if !ok {
+				note = &Note{
+					Package: b.pkgs[named.Obj().Pkg()],
+					Node:    b.target(named),
+					Kind:    NoteConstants,
+					Title:   named.Obj().Name(),
+					Lines:   []string{},
+				}
+				notes[named] = note
+				b.graph.AddNote(note)
+			}
+			note.Lines = append(note.Lines, obj.Name())
+
+		// declared variable
+		case *types.Var:
+			b.field(b.utility(obj.Pkg(), "globals", KindGlobals), obj, "")
+		}
+	}
+
+	for _, named := range enums {
+		b.enum(b.graph.Node(b.target(named)), consts[named])
+	}
+
+	// functions come after the types, as constructors are listed in them
+	for _, obj := range objects {
+		if f, ok := obj.(*types.Func); ok {
+			b.function(f)
+		}
+	}
+
+	for _, src := range srcs {
+		b.errors(src)
+	}
+
+	b.implements()
+	if b.opts.Roles || b.opts.Layers {
+		b.implementations()
+	}
+}
+
+// ignored reports whether obj is hidden with //gouml:ignore.
+func (b *builder) ignored(obj types.Object) bool {
+	d, ok := b.dirs[obj]
+	return ok && d.ignore
+}
+
+func (b *builder) collapsed(obj *types.TypeName) bool {
+	return obj.IsAlias() && b.opts.CollapseAliases
+}
+
+// target returns the ID of the node typ is drawn as, or "" if typ is not
+// declared in the loaded packages.
+func (b *builder) target(typ types.Type) string {
+	var obj *types.TypeName
+	switch t := typ.(type) {
+	case *types.Alias:
+		if b.opts.CollapseAliases {
+			return b.target(types.Unalias(t))
+		}
+		obj = t.Obj()
+	case *types.Named:
+		obj = t.Obj()
+	default:
+		return ""
+	}
+	id := naming.ObjID(obj)
+	if _, ok := b.ex[id]; !ok {
+		return ""
+	}
+	return id
+}
+
+func (b *builder) unalias(typ types.Type) types.Type {
+	if b.opts.CollapseAliases {
+		return types.Unalias(typ)
+	}
+	return typ
+}
+
+func (b *builder) node(obj *types.TypeName) *Node {
+	// *types.TypeName represents ```type [typ] [underlying]```
+	n := &Node{
+		ID:         naming.ObjID(obj),
+		Name:       obj.Name(),
+		Package:    b.pkgs[obj.Pkg()],
+		TypeParams: []*TypeParam{},
+		Fields:     []*Field{},
+		Methods:    []*Method{},
+		Terms:      []string{},
+		Constants:  []*Constant{},
+		Obj:        obj,
+	}
+
+	if b.opts.Docs && b.docs[obj] != "" {
+		n.Doc = b.docs[obj]
+		b.graph.AddNote(&Note{
+			Package: n.Package,
+			Node:    n.ID,
+			Kind:    NoteDoc,
+			Title:   obj.Name(),
+			Lines:   strings.Split(n.Doc, "\n"),
+		})
+	}
+
+	if d, ok := b.dirs[obj]; ok {
+		n.Stereotype = d.stereotype
+		n.Color = d.color
+		if len(d.notes) > 0 {
+			b.graph.AddNote(&Note{
+				Package: n.Package,
+				Node:    n.ID,
+				Kind:    NoteText,
+				Title:   obj.Name(),
+				Lines:   d.notes,
+			})
+		}
+	}
+
+	if obj.IsAlias() {
+		b.alias(n, obj)
+		return n
+	}
+
+	// get type
+	typ := obj.Type()
+	command := false
+
+	// underlying
+	switch un := typ.Underlying().(type) {
+	// struct
+	case *types.Struct:
+		for i := 0; i < un.NumFields(); i++ {
+			b.field(n, un.Field(i), un.Tag(i))
+		}
+
+	// interface
+	case *types.Interface:
+		n.Kind = KindInterface
+		if !un.IsMethodSet() {
+			// a constraint, e.g. interface{ ~int | ~float64 }
+			n.Kind = KindConstraint
+			for i := 0; i < un.NumEmbeddeds(); i++ {
+				n.Terms = append(n.Terms, naming.TypeLabel(un.EmbeddedType(i)))
+			}
+		}
+		for i := 0; i < un.NumExplicitMethods(); i++ {
+			b.method(n, un.ExplicitMethod(i))
+		}
+		for i := 0; i < un.NumEmbeddeds(); i++ {
+			b.extend(n, un.EmbeddedType(i))
+		}
+
+	// wrap
+	case *types.Slice:
+		b.wrap(n, un.Elem())
+	case *types.Map:
+		b.wrap(n, un.Elem())
+
+	// first-class function
+	case *types.Signature:
+		b.method(n, types.NewFunc(token.NoPos, obj.Pkg(), obj.Name(), un))
+	}
+
+	// named type (means user-defined class in OOP)
+	if named, _ := typ.(*types.Named); named != nil {
+
+		// type parameters
+		for i := 0; i < named.TypeParams().Len(); i++ {
+			b.typeParam(n, named.TypeParams().At(i))
+		}
+
+		// implemented methods
+		for i := 0; i < named.NumMethods(); i++ {
+			f := named.Method(i)
+			command = command || isCommand(f)
+			b.method(n, f)
+		}
+	}
+
+	// methods promoted from embedded fields
+	if st, ok := typ.Underlying().(*types.Struct); ok && !b.opts.HidePromoted {
+		b.promotedFields(n, typ, st)
+	}
+
+	if n.Kind == "" {
+		n.Kind = b.opts.Classification.kind(command)
+	}
+	b.classify(n, obj)
+	if b.opts.Roles || b.opts.Layers {
+		n.Role = b.role(obj)
+		n.Layer = b.layerOf(n.Role)
+	}
+	return n
+}
+
+func (b *builder) field(n *Node, v *types.Var, tag string) {
+	if b.ignored(v) {
+		return
+	}
+	if v.Anonymous() {
+		// an embedded type in the diagram is drawn as an EdgeEmbed instead of a field
+		if typ, _ := b.elem(v.Type()); b.target(typ) != "" {
+			b.embed(n, b.target(typ), EdgeEmbed)
+			b.typeArgs(n, typ)
+			return
+		}
+	}
+
+	n.Fields = append(n.Fields, &Field{
+		Name:      v.Name(),
+		Type:      naming.TypeLabel(v.Type()),
+		Exported:  v.Exported(),
+		Embedded:  v.Anonymous(),
+		StructTag: tag,
+		Obj:       v,
+	})
+	if b.opts.Tags {
+		n.Fields[len(n.Fields)-1].Tag = tag
+	}
+	n.Fields[len(n.Fields)-1].Doc = b.memberDoc(v)
+
+	b.refer(n, v.Type(), EdgeField, true)
+}
+
+func (b *builder) embed(n *Node, to string, kind EdgeKind) {
+	b.graph.AddEdge(&Edge{From: n.ID, To: to, Kind: kind})
+	b.embeds[[2]string{n.ID, to}] = struct{}{}
+}
+
+// extend handles an element embedded in an interface.
+func (b *builder) extend(n *Node, typ types.Type) {
+	iface, _ := typ.Underlying().(*types.Interface)
+	if iface == nil {
+		// a type term of a constraint
+		return
+	}
+	if to := b.target(b.unalias(typ)); to != "" {
+		b.embed(n, to, EdgeExtend)
+	}
+	if b.opts.HidePromoted {
+		return
+	}
+	from := naming.TypeLabel(typ)
+	for i := 0; i < iface.NumMethods(); i++ {
+		b.promoted(n, iface.Method(i), from)
+	}
+}
+
+// promotedFields adds the methods promoted from the embedded fields of st,
+// grouped by embedded field.
+func (b *builder) promotedFields(n *Node, typ types.Type, st *types.Struct) {
+	byField := make([][]*types.Func, st.NumFields())
+	mset := types.NewMethodSet(types.NewPointer(typ))
+	for i := 0; i < mset.Len(); i++ {
+		sel := mset.At(i)
+		if len(sel.Index()) < 2 {
+			// declared on typ itself
+			continue
+		}
+		f := sel.Obj().(*types.Func)
+		if !f.Exported() && f.Pkg() != n.Obj.Pkg() {
+			// not accessible
+			continue
+		}
+		byField[sel.Index()[0]] = append(byField[sel.Index()[0]], f)
+	}
+	for i, fs := range byField {
+		typ := st.Field(i).Type()
+		if ptr, ok := typ.(*types.Pointer); ok {
+			typ = ptr.Elem()
+		}
+		for _, f := range fs {
+			b.promoted(n, f, naming.TypeLabel(typ))
+		}
+	}
+}
+
+// promoted adds a method promoted from the embedded type labeled from.
+// It draws no edges: they belong to the embedded type.
+func (b *builder) promoted(n *Node, f *types.Func, from string) {
+	if b.ignored(f) {
+		return
+	}
+	for _, m := range n.Methods {
+		if m.Name == f.Name() {
+			// explicit, or already promoted through another embedded interface
+			return
+		}
+	}
+	sig := f.Type().(*types.Signature)
+	n.Methods = append(n.Methods, &Method{
+		Name:         f.Name(),
+		Exported:     f.Exported(),
+		Params:       params(sig.Params()),
+		Results:      params(sig.Results()),
+		PromotedFrom: from,
+	})
+}
+
+func (b *builder) typeParam(n *Node, tp *types.TypeParam) {
+	n.TypeParams = append(n.TypeParams, &TypeParam{
+		Name:       tp.Obj().Name(),
+		Constraint: naming.TypeLabel(tp.Constraint()),
+	})
+	b.refer(n, tp.Constraint(), EdgeConstraint, false)
+}
+
+func (b *builder) method(n *Node, f *types.Func) {
+	if b.ignored(f) {
+		return
+	}
+	b.owners[f] = n
+	// *types.Func.Type() is always a *types.Signature
+	sig := f.Type().(*types.Signature)
+	n.Methods = append(n.Methods, &Method{
+		Name:     f.Name(),
+		Exported: f.Exported(),
+		Params:   params(sig.Params()),
+		Results:  params(sig.Results()),
+		Doc:      b.memberDoc(f),
+	})
+
+	if !f.Exported() {
+		// a non-exported method do not draw a diagram.
+		return
+	}
+	b.uses(n, sig.Params(), EdgeUse)
+	b.uses(n, sig.Results(), EdgeReturn)
+}
+
+// function adds a package-level function to the type it constructs, or
+// otherwise to the utility node of its package.
+func (b *builder) function(f *types.Func) {
+	sig := f.Type().(*types.Signature)
+	if n := b.constructs(f.Pkg(), sig); n != nil {
+		b.owners[f] = n
+		// the promoted methods, added with the type, stay last
+		i := len(n.Methods)
+		for i > 0 && n.Methods[i-1].PromotedFrom != "" {
+			i--
+		}
+		n.Methods = slices.Insert(n.Methods, i, &Method{
+			Name:     f.Name(),
+			Exported: f.Exported(),
+			Static:   true,
+			Params:   params(sig.Params()),
+			Results:  params(sig.Results()),
+			Doc:      b.memberDoc(f),
+		})
+		if !f.Exported() {
+			return
+		}
+		b.uses(n, sig.Params(), EdgeUse)
+		// the constructed type itself needs no edge
+		for i := 1; i < sig.Results().Len(); i++ {
+			b.refer(n, sig.Results().At(i).Type(), EdgeReturn, false)
+		}
+		return
+	}
+	if (b.opts.Roles || b.opts.Layers) && isFactory(sig) {
+		n := b.utility(f.Pkg(), "factories", KindUtility)
+		n.Role = RoleFactory
+		n.Layer = b.layerOf(n.Role)
+		b.method(n, f)
+		return
+	}
+	b.method(b.utility(f.Pkg(), "functions", KindUtility), f)
+}
+
+// constructs returns the node of the type a function of pkg with the
+// signature sig constructs, or nil. A constructor returns a value or a pointer
+// of a non-interface type declared in pkg as its first result.
+func (b *builder) constructs(pkg *types.Package, sig *types.Signature) *Node {
+	if sig.Results().Len() == 0 {
+		return nil
+	}
+	typ := types.Unalias(sig.Results().At(0).Type())
+	if ptr, ok := typ.(*types.Pointer); ok {
+		typ = types.Unalias(ptr.Elem())
+	}
+	named, _ := typ.(*types.Named)
+	if named == nil || named.Obj().Pkg() != pkg || types.IsInterface(named) {
+		return nil
+	}
+	return b.graph.Node(naming.NamedID(named))
+}
+
+// utility returns the node named name gathering package-level objects of pkg,
+// adding it on first use.
+func (b *builder) utility(pkg *types.Package, name string, kind Kind) *Node {
+	id := naming.UtilityID(pkg.Path(), name)
+	if n, ok := b.utilities[id]; ok {
+		return n
+	}
+	n := &Node{
+		ID:         id,
+		Name:       name,
+		Package:    b.pkgs[pkg],
+		Kind:       kind,
+		TypeParams: []*TypeParam{},
+		Fields:     []*Field{},
+		Methods:    []*Method{},
+		Terms:      []string{},
+		Constants:  []*Constant{},
+	}
+	b.utilities[id] = n
+	b.graph.AddNode(n)
+	return n
+}
+
+// enum makes n an enum of the constants cs, listed in declaration order.
+func (b *builder) enum(n *Node, cs []*types.Const) {
+	sort.SliceStable(cs, func(i, j int) bool {
+		return cs[i].Pos() < cs[j].Pos()
+	})
+	n.Kind = KindEnum
+	for _, c := range cs {
+		name := c.Name()
+		if c.Pkg() != n.Obj.Pkg() {
+			name = c.Pkg().Name() + "." + name
+		}
+		n.Constants = append(n.Constants, &Constant{
+			Name:  name,
+			Value: c.Val().String(),
+			Doc:   b.memberDoc(c),
+		})
+	}
+}
+
+// comments records the doc comments, or else the line comments, and the
+// directives of the objects declared in src.
+func (b *builder) comments(src *Source) {
+	if src.Info == nil {
+		return
+	}
+	record := func(ids []*ast.Ident, groups ...*ast.CommentGroup) {
+		doc, d := "", &directives{}
+		for _, cg := range groups {
+			if cg == nil {
+				continue
+			}
+			if doc == "" {
+				// Text leaves out the directives
+				doc = strings.TrimSpace(cg.Text())
+			}
+			d.parse(cg)
+		}
+		for _, id := range ids {
+			obj := src.Info.Defs[id]
+			if obj == nil {
+				continue
+			}
+			if doc != "" {
+				b.docs[obj] = doc
+			}
+			if !d.empty() {
+				b.dirs[obj] = d
+			}
+		}
+	}
+	for _, file := range src.Files {
+		ast.Inspect(file, func(node ast.Node) bool {
+			switch node := node.(type) {
+			case *ast.GenDecl:
+				// the doc comment of an ungrouped declaration belongs to the GenDecl
+				var doc *ast.CommentGroup
+				if !node.Lparen.IsValid() {
+					doc = node.Doc
+				}
+				for _, spec := range node.Specs {
+					switch spec := spec.(type) {
+					case *ast.TypeSpec:
+						record([]*ast.Ident{spec.Name}, spec.Doc, doc, spec.Comment)
+					case *ast.ValueSpec:
+						record(spec.Names, spec.Doc, doc, spec.Comment)
+					}
+				}
+			case *ast.FuncDecl:
+				record([]*ast.Ident{node.Name}, node.Doc)
+			case *ast.Field:
+				// fields of structs and methods of interfaces
+				record(node.Names, node.Doc, node.Comment)
+			}
+			return true
+		})
+	}
+}
+
+func (b *builder) memberDoc(obj types.Object) string {
+	if !b.opts.Docs {
+		return ""
+	}
+	return b.summary(obj)
+}
+
+// summary returns the comment of obj on one line, cut at Options.DocLimit.
+func (b *builder) summary(obj types.Object) string {
+	doc := strings.Join(strings.Fields(b.docs[obj]), " ")
+	if b.opts.DocLimit > 0 && len([]rune(doc)) > b.opts.DocLimit {
+		doc = string([]rune(doc)[:b.opts.DocLimit]) + "..."
+	}
+	return doc
+}
+
+// errors adds a note listing the sentinel errors returned by each function
+// and method declared in src, attached to the node it is listed in.
+func (b *builder) errors(src *Source) {
+	if src.Info == nil {
+		return
+	}
+	for _, file := range src.Files {
+		for _, decl := range file.Decls {
+			fd, ok := decl.(*ast.FuncDecl)
+			if !ok || fd.Body == nil {
+				continue
+			}
+			f, _ := src.Info.Defs[fd.Name].(*types.Func)
+			n := b.owners[f]
+			if n == nil {
+				continue
+			}
+			lines := sentinels(src, fd.Body)
+			if len(lines) == 0 {
+				continue
+			}
+			b.graph.AddNote(&Note{
+				Package: n.Package,
+				Node:    n.ID,
+				Kind:    NoteErrors,
+				Member:  f.Name(),
+				Title:   f.Name() + "() may return",
+				Lines:   lines,
+			})
+		}
+	}
+}
+
+// sentinels returns the labels of the sentinel errors in the return statements
+// of body, wrapped ones included, in order of appearance.
+func sentinels(src *Source, body *ast.BlockStmt) []string {
+	labels := []string{}
+	seen := map[*types.Var]struct{}{}
+	ast.Inspect(body, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.FuncLit:
+			// returns of a function literal are not the ones of body
+			return false
+		case *ast.ReturnStmt:
+			for _, res := range node.Results {
+				ast.Inspect(res, func(node ast.Node) bool {
+					id, ok := node.(*ast.Ident)
+					if !ok {
+						return true
+					}
+					v, _ := src.Info.Uses[id].(*types.Var)
+					if v == nil || !isSentinel(v) {
+						return true
+					}
+					if _, ok := seen[v]; ok {
+						return true
+					}
+					seen[v] = struct{}{}
+					if v.Pkg() == src.Types {
+						labels = append(labels, v.Name())
+					} else {
+						labels = append(labels, v.Pkg().Name()+"."+v.Name())
+					}
+					return true
+				})
+			}
+		}
+		return true
+	})
+	return labels
+}
+
+func (b *builder) uses(n *Node, tuple *types.Tuple, kind EdgeKind) {
+	for i := 0; i < tuple.Len(); i++ {
+		b.refer(n, tuple.At(i).Type(), kind, false)
+	}
+}
+
+func (b *builder) wrap(n *Node, elem types.Type) {
+	elem = b.unalias(elem)
+	if to := b.target(elem); to != "" {
+		b.graph.AddEdge(&Edge{From: n.ID, To: to, Kind: EdgeWrap, Multiplicity: MultiplicityMany})
+	}
+	b.typeArgs(n, elem)
+}
+
+// alias makes n a node pointing at the aliased type.
+func (b *builder) alias(n *Node, obj *types.TypeName) {
+	n.Kind = KindAlias
+	rhs := obj.Type()
+	if a, ok := rhs.(*types.Alias); ok {
+		for i := 0; i < a.TypeParams().Len(); i++ {
+			b.typeParam(n, a.TypeParams().At(i))
+		}
+		rhs = a.Rhs()
+	}
+	n.Aliased = naming.TypeLabel(rhs)
+	b.refer(n, rhs, EdgeAlias, false)
+}
+
+// refer adds an edge of kind from n to the type typ refers to.
+// An instantiation such as Cache[string, *User] refers to the generic type
+// and to each of its type arguments.
+func (b *builder) refer(n *Node, typ types.Type, kind EdgeKind, multiplicity bool) {
+	typ, mul := b.elem(typ)
+	if to := b.target(typ); to != "" {
+		e := &Edge{From: n.ID, To: to, Kind: kind}
+		if multiplicity {
+			e.Multiplicity = mul
+		}
+		b.graph.AddEdge(e)
+	}
+	b.typeArgs(n, typ)
+}
+
+func (b *builder) typeArgs(n *Node, typ types.Type) {
+	var args *types.TypeList
+	switch t := typ.(type) {
+	case *types.Named:
+		args = t.TypeArgs()
+	case *types.Alias:
+		args = t.TypeArgs()
+	}
+	for i := 0; i < args.Len(); i++ {
+		arg, _ := b.elem(args.At(i))
+		if to := b.target(arg); to != "" {
+			b.graph.AddEdge(&Edge{From: n.ID, To: to, Kind: EdgeTypeArgument})
+		}
+		b.typeArgs(n, arg)
+	}
+}
+
+func (b *builder) implements() {
+	for _, t := range b.graph.Nodes {
+		if t.Obj == nil {
+			continue
+		}
+		T := t.Obj.Type()
+		if isGeneric(T) || t.Kind == KindAlias {
+			// the result is unspecified for uninstantiated types
+			continue
+		}
+		for _, u := range b.graph.Nodes {
+			if u.Obj == nil {
+				continue
+			}
+			U := u.Obj.Type()
+			if T == U || !types.IsInterface(U) || isGeneric(U) || u.Kind == KindConstraint || u.Kind == KindAlias {
+				continue
+			}
+			if _, ok := b.embeds[[2]string{t.ID, u.ID}]; ok {
+				continue
+			}
+			if types.AssignableTo(T, U) || (!types.IsInterface(T) && types.AssignableTo(types.NewPointer(T), U)) {
+				b.graph.AddEdge(&Edge{From: t.ID, To: u.ID, Kind: EdgeImplements})
+			}
+		}
+	}
+}
+
+func isGeneric(typ types.Type) bool {
+	named, _ := typ.(*types.Named)
+	return named != nil && named.TypeParams().Len() > 0
+}
+
+func params(tuple *types.Tuple) []*Param {
+	ps := make([]*Param, 0, tuple.Len())
+	for i := 0; i < tuple.Len(); i++ {
+		v := tuple.At(i)
+		ps = append(ps, &Param{Name: v.Name(), Type: naming.TypeLabel(v.Type())})
+	}
+	return ps
+}
+
+// elem returns the type a field, parameter or result refers to, looking
+// through a pointer, a map value and a slice element.
+func (b *builder) elem(typ types.Type) (types.Type, Multiplicity) {
+	mul := MultiplicityOne
+	typ = b.unalias(typ)
+	if ptr, ok := typ.(*types.Pointer); ok {
+		typ = b.unalias(ptr.Elem())
+		mul = MultiplicityOptional
+	}
+	if m, ok := typ.(*types.Map); ok {
+		typ = b.unalias(m.Elem())
+		mul = MultiplicityMany
+	}
+	if sl, ok := typ.(*types.Slice); ok {
+		typ = b.unalias(sl.Elem())
+		mul = MultiplicityMany
+	}
+	return typ, mul
+}
@@ -0,0 +1,533 @@
+package model_test
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"reflect"
+	"testing"
+
+	"github.com/kazukousen/gouml/model"
+)
+
+func check(t *testing.T, src string) *types.Package {
+	t.Helper()
+	return source(t, src).Types
+}
+
+func source(t *testing.T, src string) *model.Source {
+	t.Helper()
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		t.Fatalf(": %+v", err)
+	}
+	conf := types.Config{
+		Importer: importer.Default(),
+	}
+	info := &types.Info{
+		Defs: map[*ast.Ident]types.Object{},
+		Uses: map[*ast.Ident]types.Object{},
+	}
+	pkg, err := conf.Check(file.Name.Name, fset, []*ast.File{file}, info)
+	if err != nil {
+		t.Fatalf(": %+v", err)
+	}
+	return &model.Source{Types: pkg, Files: []*ast.File{file}, Info: info}
+}
+
+func TestBuild(t *testing.T) {
+	src := `
+	package shop
+	import "time"
+	type Status int
+	const (
+		Open Status = iota
+		Closed
+	)
+	const timeout time.Duration = 0
+	type Item struct {
+		Status Status
+		Parent *Item
+		Tags   map[string]Tag
+	}
+	type Tag string
+	type Saver interface {
+		Save(item *Item) error
+	}
+	type store struct {
+		items []Item
+	}
+	func (s *store) Save(item *Item) error { return nil }
+	`
+	g := model.Build([]*types.Package{check(t, src)}, model.Options{})
+
+	if want := []*model.Package{{Path: "shop", Name: "shop", Imports: []*model.Import{{Path: "time"}}}}; !reflect.DeepEqual(g.Packages, want) {
+		t.Errorf("packages: got %+v, want %+v", g.Packages, want)
+	}
+
+	kinds := map[string]model.Kind{}
+	for _, n := range g.Nodes {
+		kinds[n.ID] = n.Kind
+	}
+	wantKinds := map[string]model.Kind{
+		"shop_dItem":   model.KindValueObject,
+		"shop_dSaver":  model.KindInterface,
+		"shop_dStatus": model.KindEnum,
+		"shop_dTag":    model.KindValueObject,
+		"shop_dstore":  model.KindEntity,
+	}
+	if !reflect.DeepEqual(kinds, wantKinds) {
+		t.Errorf("kinds: got %+v, want %+v", kinds, wantKinds)
+	}
+	if n := g.Node("shop_dItem"); n == nil || len(n.Fields) != 3 || n.Fields[1].Type != "*shop.Item" {
+		t.Errorf("node: got %+v", n)
+	}
+
+	edges := []model.Edge{}
+	for _, e := range g.Edges {
+		edges = append(edges, *e)
+	}
+	wantEdges := []model.Edge{
+		{From: "shop_dItem", To: "shop_dStatus", Kind: model.EdgeField, Multiplicity: model.MultiplicityOne},
+		{From: "shop_dItem", To: "shop_dItem", Kind: model.EdgeField, Multiplicity: model.MultiplicityOptional},
+		{From: "shop_dItem", To: "shop_dTag", Kind: model.EdgeField, Multiplicity: model.MultiplicityMany},
+		{From: "shop_dSaver", To: "shop_dItem", Kind: model.EdgeUse},
+		{From: "shop_dstore", To: "shop_dItem", Kind: model.EdgeField, Multiplicity: model.MultiplicityMany},
+		{From: "shop_dstore", To: "shop_dItem", Kind: model.EdgeUse},
+		{From: "shop_dstore", To: "shop_dSaver", Kind: model.EdgeImplements},
+	}
+	if !reflect.DeepEqual(edges, wantEdges) {
+		t.Errorf("edges: got %+v, want %+v", edges, wantEdges)
+	}
+
+	if cs := g.Node("shop_dStatus").Constants; len(cs) != 2 || cs[0].Name != "Open" || cs[1].Name != "Closed" {
+		t.Errorf("constants: got %+v", cs)
+	}
+	// constants of a type outside the loaded packages have nowhere to be attached
+	for _, n := range g.Nodes {
+		for _, c := range n.Constants {
+			if c.Name == "timeout" {
+				t.Errorf("constants: got timeout in %s", n.ID)
+			}
+		}
+	}
+	g = model.Build([]*types.Package{check(t, src)}, model.Options{ConstantNotes: true})
+	if len(g.Notes) != 1 || g.Notes[0].Node != "shop_dStatus" || !reflect.DeepEqual(g.Notes[0].Lines, []string{"Closed", "Open"}) {
+		t.Errorf("notes: got %+v", g.Notes)
+	}
+}
+
+func TestBuildGenerics(t *testing.T) {
+	src := `
+	package cache
+	type Number interface {
+		~int | ~float64
+	}
+	type User struct{}
+	type Cache[K comparable, V any] struct {
+		items map[K]V
+	}
+	type Counter[N Number] struct {
+		n N
+	}
+	type Service struct {
+		users Cache[string, *User]
+	}
+	`
+	g := model.Build([]*types.Package{check(t, src)}, model.Options{})
+
+	if n := g.Node("cache_dCache"); n == nil || n.Title() != "Cache[K comparable, V any]" {
+		t.Errorf("title: got %+v", n)
+	}
+	if n := g.Node("cache_dNumber"); n == nil || n.Kind != model.KindConstraint || !reflect.DeepEqual(n.Terms, []string{"~int | ~float64"}) {
+		t.Errorf("constraint: got %+v", n)
+	}
+
+	edges := []model.Edge{}
+	for _, e := range g.Edges {
+		edges = append(edges, *e)
+	}
+	wantEdges := []model.Edge{
+		{From: "cache_dCounter", To: "cache_dNumber", Kind: model.EdgeConstraint},
+		{From: "cache_dService", To: "cache_dCache", Kind: model.EdgeField, Multiplicity: model.MultiplicityOne},
+		{From: "cache_dService", To: "cache_dUser", Kind: model.EdgeTypeArgument},
+	}
+	if !reflect.DeepEqual(edges, wantEdges) {
+		t.Errorf("edges: got %+v, want %+v", edges, wantEdges)
+	}
+}
+
+func TestBuildEmbedding(t *testing.T) {
+	src := `
+	package svc
+	import "sync"
+	type Reader interface {
+		Read() error
+	}
+	type Writer interface {
+		Write() error
+	}
+	type ReadWriter interface {
+		Reader
+		Writer
+		Close() error
+	}
+	type BaseService struct{}
+	func (s *BaseService) Name() string { return "" }
+	type Service struct {
+		*BaseService
+		sync.Mutex
+	}
+	func NewService() *Service { return nil }
+	`
+	pkgs := []*types.Package{check(t, src)}
+	g := model.Build(pkgs, model.Options{})
+
+	methods := func(id string) []string {
+		ms := []string{}
+		for _, m := range g.Node(id).Methods {
+			ms = append(ms, m.PromotedFrom+":"+m.Name)
+		}
+		return ms
+	}
+	if got, want := methods("svc_dReadWriter"), []string{":Close", "svc.Reader:Read", "svc.Writer:Write"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("interface methods: got %v, want %v", got, want)
+	}
+	if got, want := methods("svc_dService"), []string{":NewService", "svc.BaseService:Name", "sync.Mutex:Lock", "sync.Mutex:TryLock", "sync.Mutex:Unlock"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("struct methods: got %v, want %v", got, want)
+	}
+	if fs := g.Node("svc_dService").Fields; len(fs) != 1 || fs[0].Name != "Mutex" || !fs[0].Embedded {
+		t.Errorf("fields: got %+v", fs)
+	}
+
+	edges := []model.Edge{}
+	for _, e := range g.Edges {
+		edges = append(edges, *e)
+	}
+	wantEdges := []model.Edge{
+		{From: "svc_dReadWriter", To: "svc_dReader", Kind: model.EdgeExtend},
+		{From: "svc_dReadWriter", To: "svc_dWriter", Kind: model.EdgeExtend},
+		{From: "svc_dService", To: "svc_dBaseService", Kind: model.EdgeEmbed},
+	}
+	if !reflect.DeepEqual(edges, wantEdges) {
+		t.Errorf("edges: got %+v, want %+v", edges, wantEdges)
+	}
+
+	g = model.Build(pkgs, model.Options{HidePromoted: true})
+	if got, want := methods("svc_dReadWriter"), []string{":Close"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("hidden: got %v, want %v", got, want)
+	}
+}
+
+func TestBuildAliases(t *testing.T) {
+	src := `
+	package repo
+	import "time"
+	type User struct{}
+	type Page[T any] struct {
+		items []T
+	}
+	type Member = User
+	type Users = Page[*User]
+	type List[T any] = Page[T]
+	type Duration = time.Duration
+	type Service struct {
+		owner   *Member
+		members Users
+		ttl     Duration
+	}
+	`
+	pkgs := []*types.Package{check(t, src)}
+	g := model.Build(pkgs, model.Options{})
+
+	aliased := map[string]string{}
+	for _, n := range g.Nodes {
+		if n.Kind == model.KindAlias {
+			aliased[n.Title()] = n.Aliased
+		}
+	}
+	wantAliased := map[string]string{
+		"Duration":    "time.Duration",
+		"List[T any]": "repo.Page[T]",
+		"Member":      "repo.User",
+		"Users":       "repo.Page[*repo.User]",
+	}
+	if !reflect.DeepEqual(aliased, wantAliased) {
+		t.Errorf("aliases: got %+v, want %+v", aliased, wantAliased)
+	}
+
+	edges := []model.Edge{}
+	for _, e := range g.Edges {
+		edges = append(edges, *e)
+	}
+	wantEdges := []model.Edge{
+		{From: "repo_dList", To: "repo_dPage", Kind: model.EdgeAlias},
+		{From: "repo_dMember", To: "repo_dUser", Kind: model.EdgeAlias},
+		{From: "repo_dService", To: "repo_dMember", Kind: model.EdgeField, Multiplicity: model.MultiplicityOptional},
+		{From: "repo_dService", To: "repo_dUsers", Kind: model.EdgeField, Multiplicity: model.MultiplicityOne},
+		{From: "repo_dService", To: "repo_dDuration", Kind: model.EdgeField, Multiplicity: model.MultiplicityOne},
+		{From: "repo_dUsers", To: "repo_dPage", Kind: model.EdgeAlias},
+		{From: "repo_dUsers", To: "repo_dUser", Kind: model.EdgeTypeArgument},
+	}
+	if !reflect.DeepEqual(edges, wantEdges) {
+		t.Errorf("edges: got %+v, want %+v", edges, wantEdges)
+	}
+
+	g = model.Build(pkgs, model.Options{CollapseAliases: true})
+	if n := g.Node("repo_dMember"); n != nil {
+		t.Errorf("collapsed: got %+v", n)
+	}
+	edges = []model.Edge{}
+	for _, e := range g.Edges {
+		edges = append(edges, *e)
+	}
+	wantEdges = []model.Edge{
+		{From: "repo_dService", To: "repo_dUser", Kind: model.EdgeField, Multiplicity: model.MultiplicityOptional},
+		{From: "repo_dService", To: "repo_dPage", Kind: model.EdgeField, Multiplicity: model.MultiplicityOne},
+		{From: "repo_dService", To: "repo_dUser", Kind: model.EdgeTypeArgument},
+	}
+	if !reflect.DeepEqual(edges, wantEdges) {
+		t.Errorf("collapsed edges: got %+v, want %+v", edges, wantEdges)
+	}
+}
+
+func TestBuildFunctions(t *testing.T) {
+	src := `
+	package app
+	type Config struct{}
+	type Server struct{}
+	type Handler interface {
+		Serve() error
+	}
+	func NewServer(c *Config) (*Server, error) { return nil, nil }
+	func NewHandler() Handler { return nil }
+	func Run(s *Server) error { return nil }
+	func validate(c Config) bool { return true }
+	`
+	g := model.Build([]*types.Package{check(t, src)}, model.Options{})
+
+	if ms := g.Node("app_dServer").Methods; len(ms) != 1 || ms[0].Name != "NewServer" || !ms[0].Static {
+		t.Errorf("constructor: got %+v", ms)
+	}
+	u := g.Node("app_x0023functions")
+	if u == nil || u.Kind != model.KindUtility || u.Obj != nil {
+		t.Fatalf("utility: got %+v", u)
+	}
+	names := []string{}
+	for _, m := range u.Methods {
+		names = append(names, m.Name)
+	}
+	if want := []string{"NewHandler", "Run", "validate"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("functions: got %v, want %v", names, want)
+	}
+
+	edges := []model.Edge{}
+	for _, e := range g.Edges {
+		edges = append(edges, *e)
+	}
+	wantEdges := []model.Edge{
+		{From: "app_x0023functions", To: "app_dHandler", Kind: model.EdgeReturn},
+		{From: "app_dServer", To: "app_dConfig", Kind: model.EdgeUse},
+		{From: "app_x0023functions", To: "app_dServer", Kind: model.EdgeUse},
+	}
+	if !reflect.DeepEqual(edges, wantEdges) {
+		t.Errorf("edges: got %+v, want %+v", edges, wantEdges)
+	}
+}
+
+func TestBuildVariables(t *testing.T) {
+	src := `
+	package store
+	import (
+		"errors"
+		"fmt"
+		"io"
+	)
+	var (
+		ErrNotFound = errors.New("not found")
+		ErrClosed   = errors.New("closed")
+		Default     = &Store{}
+		retries     = 3
+	)
+	type Store struct{}
+	func (s *Store) Get(key string) (string, error) {
+		if key == "" {
+			return "", fmt.Errorf("get %q: %w", key, ErrNotFound)
+		}
+		read := func() error { return ErrClosed }
+		_ = read
+		return "", io.EOF
+	}
+	func (s *Store) Close() error {
+		return ErrClosed
+	}
+	func Open() (*Store, error) {
+		return nil, ErrClosed
+	}
+	`
+	g := model.BuildSources([]*model.Source{source(t, src)}, model.Options{})
+
+	globals := g.Node("store_x0023globals")
+	if globals == nil || globals.Kind != model.KindGlobals {
+		t.Fatalf("globals: got %+v", globals)
+	}
+	fields := []string{}
+	for _, f := range globals.Fields {
+		fields = append(fields, f.Name+" "+f.Type)
+	}
+	if want := []string{"Default *store.Store", "ErrClosed error", "ErrNotFound error", "retries int"}; !reflect.DeepEqual(fields, want) {
+		t.Errorf("fields: got %v, want %v", fields, want)
+	}
+
+	notes := []model.Note{}
+	for _, n := range g.Notes {
+		notes = append(notes, *n)
+	}
+	pkg := g.Packages[0]
+	wantNotes := []model.Note{
+		{Package: pkg, Node: "store_dStore", Kind: model.NoteErrors, Member: "Get", Title: "Get() may return", Lines: []string{"ErrNotFound", "io.EOF"}},
+		{Package: pkg, Node: "store_dStore", Kind: model.NoteErrors, Member: "Close", Title: "Close() may return", Lines: []string{"ErrClosed"}},
+		{Package: pkg, Node: "store_dStore", Kind: model.NoteErrors, Member: "Open", Title: "Open() may return", Lines: []string{"ErrClosed"}},
+	}
+	if !reflect.DeepEqual(notes, wantNotes) {
+		t.Errorf("notes: got %+v, want %+v", notes, wantNotes)
+	}
+
+	// the syntax is needed to find the returned errors
+	if g := model.Build([]*types.Package{check(t, src)}, model.Options{}); len(g.Notes) != 0 {
+		t.Errorf("notes without syntax: got %+v", g.Notes)
+	}
+}
+
+func TestBuildEnums(t *testing.T) {
+	src := `
+	package fs
+	type Mode uint8
+	const (
+		// Read allows reading.
+		Read Mode = 1 << iota
+		Write // allows writing
+		Exec
+	)
+	func (m Mode) String() string { return "" }
+	`
+	g := model.BuildSources([]*model.Source{source(t, src)}, model.Options{Docs: true})
+
+	n := g.Node("fs_dMode")
+	if n.Kind != model.KindEnum || len(n.Methods) != 1 {
+		t.Errorf("enum: got %+v", n)
+	}
+	cs := []model.Constant{}
+	for _, c := range n.Constants {
+		cs = append(cs, *c)
+	}
+	want := []model.Constant{
+		{Name: "Read", Value: "1", Doc: "Read allows reading."},
+		{Name: "Write", Value: "2", Doc: "allows writing"},
+		{Name: "Exec", Value: "4"},
+	}
+	if !reflect.DeepEqual(cs, want) {
+		t.Errorf("constants: got %+v, want %+v", cs, want)
+	}
+	if len(g.Notes) != 0 {
+		t.Errorf("notes: got %+v", g.Notes)
+	}
+
+	// the docs of the constants are recorded with the other docs only
+	g = model.BuildSources([]*model.Source{source(t, src)}, model.Options{})
+	for _, c := range g.Node("fs_dMode").Constants {
+		if c.Doc != "" {
+			t.Errorf("without docs: got %+v", c)
+		}
+	}
+}
+
+func TestBuildDocs(t *testing.T) {
+	src := `
+	package mail
+	// Sender delivers messages.
+	//
+	// It retries on temporary errors.
+	type Sender interface {
+		// Send delivers m and reports whether it was accepted by the remote server.
+		Send(m Message) bool
+	}
+	type Message struct {
+		To string // the recipient address
+		// Body is plain text.
+		Body string
+	}
+	`
+	srcs := []*model.Source{source(t, src)}
+	g := model.BuildSources(srcs, model.Options{Docs: true, DocLimit: 30})
+
+	if n := g.Node("mail_dSender"); n.Doc != "Sender delivers messages.\n\nIt retries on temporary errors." {
+		t.Errorf("doc: got %q", n.Doc)
+	}
+	if doc := g.Node("mail_dSender").Methods[0].Doc; doc != "Send delivers m and reports wh..." {
+		t.Errorf("method doc: got %q", doc)
+	}
+	docs := []string{}
+	for _, f := range g.Node("mail_dMessage").Fields {
+		docs = append(docs, f.Doc)
+	}
+	if want := []string{"the recipient address", "Body is plain text."}; !reflect.DeepEqual(docs, want) {
+		t.Errorf("field docs: got %v, want %v", docs, want)
+	}
+	if len(g.Notes) != 1 || g.Notes[0].Kind != model.NoteDoc || g.Notes[0].Node != "mail_dSender" ||
+		!reflect.DeepEqual(g.Notes[0].Lines, []string{"Sender delivers messages.", "", "It retries on temporary errors."}) {
+		t.Errorf("notes: got %+v", g.Notes)
+	}
+
+	// comments are left out unless asked
+	g = model.BuildSources(srcs, model.Options{})
+	if len(g.Notes) != 0 || g.Node("mail_dSender").Methods[0].Doc != "" {
+		t.Errorf("without docs: got %+v", g.Notes)
+	}
+}
+
+func TestBuildDirectives(t *testing.T) {
+	src := `
+	package order
+	//gouml:stereotype aggregate
+	//gouml:color #FFAA00
+	//gouml:note consistency boundary
+	type Order struct {
+		Lines []Line
+		//gouml:ignore
+		version int
+	}
+	// Close completes the order.
+	//gouml:ignore
+	func (o *Order) Close() {}
+	func (o *Order) Total() int { return 0 }
+	type Line struct{}
+	//gouml:ignore
+	type draft struct {
+		order *Order
+	}
+	//gouml:color red
+	type Status int
+	`
+	g := model.BuildSources([]*model.Source{source(t, src)}, model.Options{Docs: true})
+
+	n := g.Node("order_dOrder")
+	if n.Stereotype != "aggregate" || n.Color != "#FFAA00" {
+		t.Errorf("directives: got %+v", n)
+	}
+	if len(n.Fields) != 1 || len(n.Methods) != 1 || n.Methods[0].Name != "Total" {
+		t.Errorf("members: got %+v %+v", n.Fields, n.Methods)
+	}
+	if g.Node("order_ddraft") != nil {
+		t.Errorf("ignored type: got %+v", g.Node("order_ddraft"))
+	}
+	if n := g.Node("order_dStatus"); n.Color != "" {
+		t.Errorf("malformed color: got %q", n.Color)
+	}
+	if len(g.Notes) != 1 || g.Notes[0].Kind != model.NoteText || !reflect.DeepEqual(g.Notes[0].Lines, []string{"consistency boundary"}) {
+		t.Errorf("notes: got %+v", g.Notes)
+	}
+}
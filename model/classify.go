@@ -0,0 +1,174 @@
+package model
+
+import (
+	"fmt"
+	"go/types"
+	"strings"
+)
+
+// Presets of a Classification, the default kinds of the struct and other
+// non-interface types before the rules apply.
+const (
+	// PresetDDD makes a type with a command method, a pointer-receiver method
+	// returning nothing or only an error, an entity and any other type a value
+	// object. It is the default.
+	PresetDDD = "ddd"
+	// PresetPlain makes every type a plain class.
+	PresetPlain = "plain"
+)
+
+// Classification maps types to stereotypes.
+type Classification struct {
+	Preset string `json:"preset,omitempty"`
+	// Rules are tried in order and the first matching one applies.
+	Rules []Rule `json:"rules,omitempty"`
+}
+
+// Rule gives Stereotype to the types matching all of its conditions.
+// A rule without conditions matches every type.
+type Rule struct {
+	// Stereotype is the stereotype given, e.g. Aggregate or Repository.
+	// Empty makes a struct a plain class.
+	Stereotype string `json:"stereotype"`
+	// Color is the fill color given, e.g. #FFAA00.
+	Color string `json:"color,omitempty"`
+
+	// Suffix matches the types named with it, e.g. DTO.
+	Suffix string `json:"suffix,omitempty"`
+	// Package matches the types declared in the package of the import path,
+	// or in the packages below it with a trailing /... .
+	Package string `json:"package,omitempty"`
+	// Implements matches the types implementing the interface, qualified by
+	// the import path, e.g. github.com/org/svc/domain.Repository.
+	Implements string `json:"implements,omitempty"`
+	// Directive matches the types with //gouml:stereotype followed by it,
+	// ignoring case. Only the rules with a Directive apply to such types.
+	Directive string `json:"directive,omitempty"`
+}
+
+// Check reports an unknown preset or a malformed rule.
+func (c Classification) Check() error {
+	switch c.Preset {
+	case "", PresetDDD, PresetPlain:
+	default:
+		return fmt.Errorf("unknown preset: %s", c.Preset)
+	}
+	for i, r := range c.Rules {
+		if strings.ContainsAny(r.Stereotype, " \t") {
+			return fmt.Errorf("rule %d: stereotype %q has spaces", i, r.Stereotype)
+		}
+		if r.Color != "" && !colorPattern.MatchString(r.Color) {
+			return fmt.Errorf("rule %d: color %q is not #hex", i, r.Color)
+		}
+		if r.Implements != "" && !strings.Contains(r.Implements, ".") {
+			return fmt.Errorf("rule %d: implements %q is not qualified by an import path", i, r.Implements)
+		}
+	}
+	return nil
+}
+
+// kind returns the kind of a non-interface type by the preset.
+func (c Classification) kind(command bool) Kind {
+	if c.Preset == PresetPlain {
+		return KindClass
+	}
+	if command {
+		return KindEntity
+	}
+	return KindValueObject
+}
+
+// classify applies the first rule matching the type of n.
+func (b *builder) classify(n *Node, obj *types.TypeName) {
+	directive := ""
+	if d, ok := b.dirs[obj]; ok {
+		directive = d.stereotype
+	}
+	for _, r := range b.opts.Classification.Rules {
+		if !b.matches(r, obj, directive) {
+			continue
+		}
+		n.Stereotype = r.Stereotype
+		if n.Color == "" {
+			n.Color = r.Color
+		}
+		if n.Kind == KindEntity || n.Kind == KindValueObject {
+			n.Kind = KindClass
+		}
+		return
+	}
+}
+
+func (b *builder) matches(r Rule, obj *types.TypeName, directive string) bool {
+	if directive != "" || r.Directive != "" {
+		// a directive wins over the other rules
+		if !strings.EqualFold(directive, r.Directive) {
+			return false
+		}
+	}
+	if r.Suffix != "" && !strings.HasSuffix(obj.Name(), r.Suffix) {
+		return false
+	}
+	if r.Package != "" && !matchPackage(r.Package, obj.Pkg().Path()) {
+		return false
+	}
+	if r.Implements != "" {
+		iface := b.lookupInterface(r.Implements)
+		T := obj.Type()
+		if iface == nil || isGeneric(T) {
+			return false
+		}
+		if !types.Implements(T, iface) && (types.IsInterface(T) || !types.Implements(types.NewPointer(T), iface)) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchPackage reports whether the import path matches pattern, an import
+// path with a trailing /... for the packages below it.
+func matchPackage(pattern, path string) bool {
+	if prefix, ok := strings.CutSuffix(pattern, "/..."); ok {
+		return path == prefix || strings.HasPrefix(path, prefix+"/")
+	}
+	return path == pattern
+}
+
+// lookupInterface returns the interface named by a qualified name such as
+// io.Reader, declared in a loaded package or in one of their imports.
+func (b *builder) lookupInterface(qualified string) *types.Interface {
+	if iface, ok := b.ifaces[qualified]; ok {
+		return iface
+	}
+	i := strings.LastIndex(qualified, ".")
+	if i < 0 {
+		// not qualified, see Classification.Check
+		b.ifaces[qualified] = nil
+		return nil
+	}
+	path, name := qualified[:i], qualified[i+1:]
+
+	var iface *types.Interface
+	seen := map[*types.Package]bool{}
+	var find func(pkg *types.Package)
+	find = func(pkg *types.Package) {
+		if iface != nil || seen[pkg] {
+			return
+		}
+		seen[pkg] = true
+		if pkg.Path() == path {
+			if tn, ok := pkg.Scope().Lookup(name).(*types.TypeName); ok {
+				iface, _ = tn.Type().Underlying().(*types.Interface)
+			}
+			return
+		}
+		for _, imp := range pkg.Imports() {
+			find(imp)
+		}
+	}
+	for pkg := range b.pkgs {
+		find(pkg)
+	}
+	b.ifaces[qualified] = iface
+	return iface
+}
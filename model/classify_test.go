@@ -0,0 +1,76 @@
+package model_test
+
+import (
+	"go/types"
+	"testing"
+
+	"github.com/kazukousen/gouml/model"
+)
+
+func TestClassification(t *testing.T) {
+	src := `
+	package app
+	import "io"
+	type OrderService struct{}
+	type UserDTO struct{}
+	type Order struct{}
+	func (o *Order) Cancel() error { return nil }
+	type Log struct{}
+	func (l *Log) Write(p []byte) (int, error) { return 0, nil }
+	//gouml:stereotype aggregate
+	type Cart struct{}
+	var _ io.Writer
+	`
+	opts := model.Options{Classification: model.Classification{
+		Preset: model.PresetPlain,
+		Rules: []model.Rule{
+			{Directive: "aggregate", Stereotype: "Aggregate", Color: "#FFAA00"},
+			{Suffix: "Service", Stereotype: "Service"},
+			{Suffix: "DTO", Package: "app/...", Stereotype: "DTO"},
+			{Implements: "io.Writer", Stereotype: "Sink"},
+		},
+	}}
+	if err := opts.Classification.Check(); err != nil {
+		t.Fatalf(": %+v", err)
+	}
+	g := model.BuildSources([]*model.Source{source(t, src)}, opts)
+
+	tests := []struct {
+		id         string
+		kind       model.Kind
+		stereotype string
+		color      string
+	}{
+		{"app_dOrderService", model.KindClass, "Service", ""},
+		{"app_dUserDTO", model.KindClass, "DTO", ""},
+		{"app_dOrder", model.KindClass, "", ""},
+		{"app_dLog", model.KindClass, "Sink", ""},
+		{"app_dCart", model.KindClass, "Aggregate", "#FFAA00"},
+	}
+	for _, tt := range tests {
+		n := g.Node(tt.id)
+		if n.Kind != tt.kind || n.Stereotype != tt.stereotype || n.Color != tt.color {
+			t.Errorf("%s: got %s <<%s>> %s, want %s <<%s>> %s", tt.id, n.Kind, n.Stereotype, n.Color, tt.kind, tt.stereotype, tt.color)
+		}
+	}
+
+	// the default preset keeps the entities and the value objects
+	g = model.Build([]*types.Package{check(t, src)}, model.Options{})
+	if k := g.Node("app_dOrder").Kind; k != model.KindEntity {
+		t.Errorf("ddd: got %s", k)
+	}
+
+	// an unqualified interface matches nothing when the classification is
+	// not checked
+	g = model.Build([]*types.Package{check(t, src)}, model.Options{Classification: model.Classification{
+		Rules: []model.Rule{{Implements: "Writer", Stereotype: "Sink"}},
+	}})
+	if s := g.Node("app_dLog").Stereotype; s != "" {
+		t.Errorf("unqualified: got %s", s)
+	}
+
+	bad := model.Classification{Preset: "onion"}
+	if err := bad.Check(); err == nil {
+		t.Errorf("unknown preset: no error")
+	}
+}
@@ -0,0 +1,212 @@
+package model
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kazukousen/gouml/internal/gouml/naming"
+)
+
+// Contract declares the layers of the packages and the directions their
+// dependencies are allowed in.
+type Contract struct {
+	Layers []*ContractLayer `json:"layers"`
+	// Acyclic forbids the layers to depend on each other in a cycle.
+	Acyclic bool `json:"acyclic,omitempty"`
+}
+
+// ContractLayer is a named group of packages.
+type ContractLayer struct {
+	Name string `json:"name"`
+	// Packages are import paths, with a trailing /... for the packages below
+	// them. A package is in the first layer matching it.
+	Packages []string `json:"packages"`
+	// Allow lists the only other layers the layer may depend on, when not
+	// nil. An empty list allows none.
+	Allow []string `json:"allow,omitempty"`
+	// Deny lists the layers the layer must not depend on.
+	Deny []string `json:"deny,omitempty"`
+}
+
+// Violation is an import breaking a Contract.
+type Violation struct {
+	// From is the import path of the importing package.
+	From      string
+	FromLayer string
+	// To is the imported path.
+	To      string
+	ToLayer string
+	Reason  string
+	// Types are the names of the types of From referring to To.
+	Types []string
+}
+
+func (v *Violation) String() string {
+	s := fmt.Sprintf("%s -> %s: %s", v.From, v.To, v.Reason)
+	if len(v.Types) > 0 {
+		s += " (" + strings.Join(v.Types, ", ") + ")"
+	}
+	return s
+}
+
+// Check reports a layer without a name or packages, or a reference to an
+// unknown layer.
+func (c *Contract) Check() error {
+	names := map[string]struct{}{}
+	for i, l := range c.Layers {
+		if l.Name == "" {
+			return fmt.Errorf("layer %d: no name", i)
+		}
+		if _, ok := names[l.Name]; ok {
+			return fmt.Errorf("layer %s: declared twice", l.Name)
+		}
+		if len(l.Packages) == 0 {
+			return fmt.Errorf("layer %s: no packages", l.Name)
+		}
+		names[l.Name] = struct{}{}
+	}
+	for _, l := range c.Layers {
+		for _, name := range append(append([]string{}, l.Allow...), l.Deny...) {
+			if _, ok := names[name]; !ok {
+				return fmt.Errorf("layer %s: unknown layer %s", l.Name, name)
+			}
+		}
+	}
+	return nil
+}
+
+// layer returns the first layer matching the import path, or nil.
+func (c *Contract) layer(path string) *ContractLayer {
+	for _, l := range c.Layers {
+		for _, pattern := range l.Packages {
+			if matchPackage(pattern, path) {
+				return l
+			}
+		}
+	}
+	return nil
+}
+
+// Violations returns the imports of the packages of g breaking c, in the
+// order of the packages and their imports, the ones in cycles last. An import
+// from or of a package in no layer is never a violation.
+func (c *Contract) Violations(g *Graph) []*Violation {
+	type layerEdge [2]*ContractLayer
+	// the layers each layer depends on, to find the cycles
+	depends := map[*ContractLayer]map[*ContractLayer]struct{}{}
+	vs := []*Violation{}
+	cycles := map[layerEdge][]*Violation{}
+	for _, pkg := range g.Packages {
+		from := c.layer(pkg.Path)
+		if from == nil {
+			continue
+		}
+		for _, imp := range pkg.Imports {
+			to := c.layer(imp.Path)
+			if to == nil || to == from {
+				continue
+			}
+			if depends[from] == nil {
+				depends[from] = map[*ContractLayer]struct{}{}
+			}
+			depends[from][to] = struct{}{}
+
+			v := &Violation{From: pkg.Path, FromLayer: from.Name, To: imp.Path, ToLayer: to.Name, Types: referring(g, pkg, imp.Path)}
+			switch {
+			case contains(from.Deny, to.Name):
+				v.Reason = fmt.Sprintf("%s must not depend on %s", from.Name, to.Name)
+			case from.Allow != nil && !contains(from.Allow, to.Name):
+				v.Reason = fmt.Sprintf("%s may only depend on %s", from.Name, allowed(from.Allow))
+			}
+			if v.Reason != "" {
+				vs = append(vs, v)
+				continue
+			}
+			if c.Acyclic {
+				e := layerEdge{from, to}
+				cycles[e] = append(cycles[e], v)
+			}
+		}
+	}
+
+	if !c.Acyclic {
+		return vs
+	}
+	var reaches func(from, to *ContractLayer, seen map[*ContractLayer]bool) bool
+	reaches = func(from, to *ContractLayer, seen map[*ContractLayer]bool) bool {
+		if from == to {
+			return true
+		}
+		if seen[from] {
+			return false
+		}
+		seen[from] = true
+		for next := range depends[from] {
+			if reaches(next, to, seen) {
+				return true
+			}
+		}
+		return false
+	}
+	cyclic := []*Violation{}
+	for e, candidates := range cycles {
+		if !reaches(e[1], e[0], map[*ContractLayer]bool{}) {
+			continue
+		}
+		for _, v := range candidates {
+			v.Reason = fmt.Sprintf("cycle between %s and %s", e[0].Name, e[1].Name)
+			cyclic = append(cyclic, v)
+		}
+	}
+	sort.Slice(cyclic, func(i, j int) bool {
+		if cyclic[i].From != cyclic[j].From {
+			return cyclic[i].From < cyclic[j].From
+		}
+		return cyclic[i].To < cyclic[j].To
+	})
+	return append(vs, cyclic...)
+}
+
+// Mark marks the dependencies of d standing for an import of vs.
+func (d *Deps) Mark(vs []*Violation) {
+	for _, v := range vs {
+		from := naming.EscapeID(v.From)
+		for _, dep := range d.Dependencies {
+			if dep.From == from && contains(dep.Imports, v.To) {
+				dep.Violation = true
+			}
+		}
+	}
+}
+
+// referring returns the names of the types of pkg referring to the package
+// of the import path.
+func referring(g *Graph, pkg *Package, path string) []string {
+	names := []string{}
+	for _, n := range g.NodesOf(pkg) {
+		if n.Obj == nil {
+			continue
+		}
+		if _, ok := references(n.Obj)[path]; ok {
+			names = append(names, n.Name)
+		}
+	}
+	return names
+}
+
+func allowed(names []string) string {
+	if len(names) == 0 {
+		return "itself"
+	}
+	return strings.Join(names, ", ")
+}
+
+func contains(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
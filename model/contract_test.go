@@ -0,0 +1,60 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/kazukousen/gouml/model"
+)
+
+func TestContract(t *testing.T) {
+	g := model.NewGraph()
+	for _, pkg := range []*model.Package{
+		{Path: "svc/domain", Imports: []*model.Import{{Path: "svc/infra/db"}, {Path: "time"}}},
+		{Path: "svc/app", Imports: []*model.Import{{Path: "svc/domain"}, {Path: "svc/infra/db"}}},
+		{Path: "svc/infra/db", Imports: []*model.Import{{Path: "svc/app"}, {Path: "svc/domain"}}},
+	} {
+		g.AddPackage(pkg)
+	}
+	contract := &model.Contract{
+		Layers: []*model.ContractLayer{
+			{Name: "domain", Packages: []string{"svc/domain"}, Deny: []string{"infra"}},
+			{Name: "app", Packages: []string{"svc/app"}, Allow: []string{"domain"}},
+			{Name: "infra", Packages: []string{"svc/infra/..."}},
+		},
+		Acyclic: true,
+	}
+	if err := contract.Check(); err != nil {
+		t.Fatalf(": %+v", err)
+	}
+
+	want := []string{
+		"svc/domain -> svc/infra/db: domain must not depend on infra",
+		"svc/app -> svc/infra/db: app may only depend on domain",
+		// domain reaches app through infra
+		"svc/app -> svc/domain: cycle between app and domain",
+		"svc/infra/db -> svc/app: cycle between infra and app",
+		"svc/infra/db -> svc/domain: cycle between infra and domain",
+	}
+	vs := contract.Violations(g)
+	if len(vs) != len(want) {
+		t.Fatalf("got %d violations, want %d: %v", len(vs), len(want), vs)
+	}
+	for i, v := range vs {
+		if v.String() != want[i] {
+			t.Errorf("%d: got %s, want %s", i, v, want[i])
+		}
+	}
+
+	d := model.BuildDeps(g, false)
+	d.Mark(vs[:1])
+	for _, dep := range d.Dependencies {
+		if violation := dep.From == "svc_sdomain" && dep.To == "svc_sinfra_sdb"; dep.Violation != violation {
+			t.Errorf("%s -> %s: got violation %v", dep.From, dep.To, dep.Violation)
+		}
+	}
+
+	unknown := &model.Contract{Layers: []*model.ContractLayer{{Name: "domain", Packages: []string{"svc/domain"}, Deny: []string{"infra"}}}}
+	if err := unknown.Check(); err == nil {
+		t.Errorf("unknown layer: no error")
+	}
+}
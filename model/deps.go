@@ -0,0 +1,218 @@
+package model
+
+import (
+	"go/types"
+	"strings"
+
+	"github.com/kazukousen/gouml/internal/gouml/naming"
+)
+
+// Deps is the package-level view of a Graph: the loaded packages and the
+// imports between them, optionally with the imported modules.
+type Deps struct {
+	Packages     []*DepPackage
+	Dependencies []*Dependency
+}
+
+// DepPackage is a loaded package, or an external module collapsing the
+// imported packages of it.
+type DepPackage struct {
+	ID string
+	// Path is the import path, or the module path of a module.
+	Path string
+	// Module is true for an external module.
+	Module bool
+	// Types is the number of the types drawn for a loaded package.
+	Types int
+}
+
+// Dependency means From imports To.
+type Dependency struct {
+	From string
+	To   string
+	// Types is the number of the types of From referring to a type of To.
+	Types int
+	// Imports are the import paths To stands for, more than one for a module.
+	Imports []string
+	// Violation is true for an import breaking a Contract, see Deps.Mark.
+	Violation bool
+}
+
+// ModuleStd is the module path the packages of the standard library are
+// collapsed into.
+const ModuleStd = "std"
+
+// BuildDeps analyses the imports of the packages of g. With external, the
+// packages imported from outside of g are collapsed by module path.
+func BuildDeps(g *Graph, external bool) *Deps {
+	d := &Deps{Packages: []*DepPackage{}, Dependencies: []*Dependency{}}
+	loaded := map[string]*DepPackage{}
+	for _, pkg := range g.Packages {
+		p := &DepPackage{ID: naming.EscapeID(pkg.Path), Path: pkg.Path}
+		d.Packages = append(d.Packages, p)
+		loaded[pkg.Path] = p
+	}
+
+	modules := map[string]*DepPackage{}
+	ms := []*DepPackage{}
+	// to returns the package or the module an import path is drawn as, or nil.
+	to := func(imp *Import) *DepPackage {
+		if p, ok := loaded[imp.Path]; ok {
+			return p
+		}
+		if !external {
+			return nil
+		}
+		path := module(imp)
+		m, ok := modules[path]
+		if !ok {
+			m = &DepPackage{ID: naming.UtilityID(path, "module"), Path: path, Module: true}
+			modules[path] = m
+			ms = append(ms, m)
+		}
+		return m
+	}
+
+	for _, pkg := range g.Packages {
+		from := loaded[pkg.Path]
+		imports := map[string]*Import{}
+		for _, imp := range pkg.Imports {
+			imports[imp.Path] = imp
+		}
+
+		// the types referring to each package or module
+		referring := map[*DepPackage]map[*Node]struct{}{}
+		for _, n := range g.NodesOf(pkg) {
+			if n.Obj == nil {
+				continue
+			}
+			from.Types++
+			for path := range references(n.Obj) {
+				imp, ok := imports[path]
+				if !ok {
+					continue
+				}
+				if p := to(imp); p != nil {
+					if referring[p] == nil {
+						referring[p] = map[*Node]struct{}{}
+					}
+					referring[p][n] = struct{}{}
+				}
+			}
+		}
+
+		deps := map[*DepPackage]*Dependency{}
+		for _, imp := range pkg.Imports {
+			p := to(imp)
+			if p == nil {
+				continue
+			}
+			dep, ok := deps[p]
+			if !ok {
+				dep = &Dependency{From: from.ID, To: p.ID, Types: len(referring[p])}
+				deps[p] = dep
+				d.Dependencies = append(d.Dependencies, dep)
+			}
+			dep.Imports = append(dep.Imports, imp.Path)
+		}
+	}
+
+	// the modules follow the loaded packages, in order of first import
+	d.Packages = append(d.Packages, ms...)
+	return d
+}
+
+// module returns the module path imp is collapsed into.
+func module(imp *Import) string {
+	if imp.Module != "" {
+		return imp.Module
+	}
+	// the first element of the paths of the standard library has no dot
+	first, _, _ := strings.Cut(imp.Path, "/")
+	if !strings.Contains(first, ".") {
+		return ModuleStd
+	}
+	return imp.Path
+}
+
+// references returns the import paths of the packages of the types obj
+// refers to in its declaration and its methods.
+func references(obj *types.TypeName) map[string]struct{} {
+	paths := map[string]struct{}{}
+	seen := map[types.Type]struct{}{}
+	var walk func(typ types.Type)
+	walk = func(typ types.Type) {
+		if _, ok := seen[typ]; ok {
+			return
+		}
+		seen[typ] = struct{}{}
+		switch t := typ.(type) {
+		case *types.Alias:
+			if p := t.Obj().Pkg(); p != nil {
+				paths[p.Path()] = struct{}{}
+			}
+			for i := 0; i < t.TypeArgs().Len(); i++ {
+				walk(t.TypeArgs().At(i))
+			}
+		case *types.Named:
+			if p := t.Obj().Pkg(); p != nil {
+				paths[p.Path()] = struct{}{}
+			}
+			for i := 0; i < t.TypeArgs().Len(); i++ {
+				walk(t.TypeArgs().At(i))
+			}
+		case *types.Pointer:
+			walk(t.Elem())
+		case *types.Slice:
+			walk(t.Elem())
+		case *types.Array:
+			walk(t.Elem())
+		case *types.Map:
+			walk(t.Key())
+			walk(t.Elem())
+		case *types.Chan:
+			walk(t.Elem())
+		case *types.Signature:
+			for _, tuple := range []*types.Tuple{t.Params(), t.Results()} {
+				for i := 0; i < tuple.Len(); i++ {
+					walk(tuple.At(i).Type())
+				}
+			}
+		case *types.Struct:
+			for i := 0; i < t.NumFields(); i++ {
+				walk(t.Field(i).Type())
+			}
+		case *types.Interface:
+			for i := 0; i < t.NumMethods(); i++ {
+				walk(t.Method(i).Type())
+			}
+			for i := 0; i < t.NumEmbeddeds(); i++ {
+				walk(t.EmbeddedType(i))
+			}
+		case *types.TypeParam:
+			walk(t.Constraint())
+		case *types.Union:
+			for i := 0; i < t.Len(); i++ {
+				walk(t.Term(i).Type())
+			}
+		}
+	}
+
+	if alias, ok := obj.Type().(*types.Alias); ok {
+		walk(alias.Rhs())
+		delete(paths, obj.Pkg().Path())
+		return paths
+	}
+	typ := obj.Type()
+	walk(typ.Underlying())
+	if named, ok := typ.(*types.Named); ok {
+		for i := 0; i < named.TypeParams().Len(); i++ {
+			walk(named.TypeParams().At(i).Constraint())
+		}
+		for i := 0; i < named.NumMethods(); i++ {
+			walk(named.Method(i).Type())
+		}
+	}
+	delete(paths, obj.Pkg().Path())
+	return paths
+}
@@ -0,0 +1,93 @@
+package model_test
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"reflect"
+	"testing"
+
+	"github.com/kazukousen/gouml/model"
+)
+
+type importerFunc func(path string) (*types.Package, error)
+
+func (f importerFunc) Import(path string) (*types.Package, error) {
+	return f(path)
+}
+
+func TestBuildDeps(t *testing.T) {
+	domain := source(t, `
+	package domain
+	import "time"
+	type User struct{ CreatedAt time.Time }
+	type Name string
+	`)
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", `
+	package app
+	import (
+		"domain"
+		"strings"
+		"example.com/lib/log"
+	)
+	type Service struct{ users []*domain.User }
+	type Form struct{ Name domain.Name }
+	type Logger = log.Logger
+	func Trim(s string) string { return strings.TrimSpace(s) }
+	`, 0)
+	if err != nil {
+		t.Fatalf(": %+v", err)
+	}
+	log := types.NewPackage("example.com/lib/log", "log")
+	logger := types.NewTypeName(token.NoPos, log, "Logger", nil)
+	types.NewNamed(logger, types.NewStruct(nil, nil), nil)
+	log.Scope().Insert(logger)
+	log.MarkComplete()
+	conf := types.Config{Importer: importerFunc(func(path string) (*types.Package, error) {
+		switch path {
+		case "domain":
+			return domain.Types, nil
+		case log.Path():
+			return log, nil
+		}
+		return importer.Default().Import(path)
+	})}
+	app, err := conf.Check("app", fset, []*ast.File{file}, nil)
+	if err != nil {
+		t.Fatalf(": %+v", err)
+	}
+	srcs := []*model.Source{
+		{Types: app, Modules: map[string]string{log.Path(): "example.com/lib"}},
+		domain,
+	}
+	g := model.BuildSources(srcs, model.Options{})
+
+	d := model.BuildDeps(g, false)
+	want := []*model.Dependency{{From: "app", To: "domain", Types: 2, Imports: []string{"domain"}}}
+	if !reflect.DeepEqual(d.Dependencies, want) {
+		t.Errorf("loaded: got %+v", d.Dependencies)
+	}
+	if d.Packages[0].Types != 3 || d.Packages[1].Types != 2 {
+		t.Errorf("types: got %d, %d", d.Packages[0].Types, d.Packages[1].Types)
+	}
+
+	d = model.BuildDeps(g, true)
+	want = []*model.Dependency{
+		{From: "app", To: "domain", Types: 2, Imports: []string{"domain"}},
+		{From: "app", To: "example_dcom_slib_x0023module", Types: 1, Imports: []string{"example.com/lib/log"}},
+		{From: "app", To: "std_x0023module", Types: 0, Imports: []string{"strings"}},
+		{From: "domain", To: "std_x0023module", Types: 1, Imports: []string{"time"}},
+	}
+	if !reflect.DeepEqual(d.Dependencies, want) {
+		for _, dep := range d.Dependencies {
+			t.Logf("%+v", dep)
+		}
+		t.Errorf("external: not equal")
+	}
+	if len(d.Packages) != 4 || !d.Packages[2].Module || d.Packages[2].Path != "example.com/lib" || d.Packages[3].Path != model.ModuleStd {
+		t.Errorf("modules: got %+v", d.Packages[2:])
+	}
+}
@@ -0,0 +1,54 @@
+package model
+
+import (
+	"go/ast"
+	"regexp"
+	"strings"
+)
+
+const directivePrefix = "//gouml:"
+
+var colorPattern = regexp.MustCompile(`^#(?:[0-9A-Fa-f]{3}|[0-9A-Fa-f]{6})$`)
+
+// directives are the `//gouml:` comments of a declaration:
+//
+//	//gouml:ignore                hides a type or a member
+//	//gouml:stereotype aggregate  replaces the stereotype of a type
+//	//gouml:color #FFAA00         fills a type with a color
+//	//gouml:note text             attaches a note with the text to a type
+type directives struct {
+	ignore     bool
+	stereotype string
+	color      string
+	notes      []string
+}
+
+// parse adds the directives found in cg. Unknown or malformed directives are
+// skipped.
+func (d *directives) parse(cg *ast.CommentGroup) {
+	for _, c := range cg.List {
+		if !strings.HasPrefix(c.Text, directivePrefix) {
+			continue
+		}
+		name, arg, _ := strings.Cut(strings.TrimPrefix(c.Text, directivePrefix), " ")
+		arg = strings.TrimSpace(arg)
+		switch name {
+		case "ignore":
+			d.ignore = true
+		case "stereotype":
+			if arg != "" && !strings.ContainsAny(arg, " \t") {
+				d.stereotype = arg
+			}
+		case "color":
+			if colorPattern.MatchString(arg) {
+				d.color = arg
+			}
+		case "note":
+			d.notes = append(d.notes, arg)
+		}
+	}
+}
+
+func (d *directives) empty() bool {
+	return !d.ignore && d.stereotype == "" && d.color == "" && len(d.notes) == 0
+}
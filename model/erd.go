@@ -0,0 +1,340 @@
+package model
+
+import (
+	"go/types"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/kazukousen/gouml/internal/gouml/naming"
+)
+
+// ERD is the entity-relationship view of a Graph: the structs with `db` or
+// `gorm` tags are tables, and their foreign keys relate them.
+type ERD struct {
+	Tables    []*Table
+	Relations []*Relation
+}
+
+// Table is a struct mapped to a database table.
+type Table struct {
+	// ID is the ID of the node of the struct.
+	ID string
+	// Name is the table name: the struct name in snake_case.
+	Name    string
+	Columns []*Column
+}
+
+// Column is a field of a Table.
+type Column struct {
+	Name string
+	// Type is the label of the Go type.
+	Type       string
+	PrimaryKey bool
+	// ForeignKey is the ID of the referenced Table, if any.
+	ForeignKey string
+	// Nullable is true for a pointer or a sql.Null* type.
+	Nullable bool
+}
+
+// Relation means many rows of From refer to one row of To with the
+// foreign key Column.
+type Relation struct {
+	From   string
+	To     string
+	Column string
+}
+
+// BuildERD analyses the tables of g.
+func BuildERD(g *Graph) *ERD {
+	e := &ERD{Tables: []*Table{}, Relations: []*Relation{}}
+	tables := map[string]*Table{}
+	// byName holds the tables by name, which prefixes a foreign key column
+	// by convention, e.g. user_id.
+	byName := map[string]*Table{}
+	// embeds holds the nodes each node embeds
+	embeds := map[string][]*Node{}
+	for _, e := range g.Edges {
+		if e.Kind == EdgeEmbed {
+			embeds[e.From] = append(embeds[e.From], g.Node(e.To))
+		}
+	}
+	for _, n := range g.Nodes {
+		if n.Obj == nil || n.Kind == KindAlias {
+			continue
+		}
+		if _, ok := n.Obj.Type().Underlying().(*types.Struct); !ok || !mapped(n, embeds) {
+			continue
+		}
+		t := &Table{ID: n.ID, Name: snake(n.Name), Columns: []*Column{}}
+		nodeColumns(t, n, embeds, isGorm(n.Fields), map[*Node]bool{})
+		e.Tables = append(e.Tables, t)
+		tables[t.ID] = t
+		byName[t.Name] = t
+	}
+
+	seen := map[Relation]struct{}{}
+	relate := func(from *Table, column string, to *Table) {
+		for _, c := range from.Columns {
+			if c.Name != column {
+				continue
+			}
+			c.ForeignKey = to.ID
+			r := Relation{From: from.ID, To: to.ID, Column: column}
+			if _, ok := seen[r]; !ok {
+				seen[r] = struct{}{}
+				e.Relations = append(e.Relations, &r)
+			}
+		}
+	}
+
+	for _, t := range e.Tables {
+		// associations of gorm, e.g. `Company Company` or `Orders []Order`
+		for _, f := range g.Node(t.ID).Fields {
+			if f.Obj == nil {
+				continue
+			}
+			typ := types.Unalias(f.Obj.Type())
+			many := false
+			if ptr, ok := typ.(*types.Pointer); ok {
+				typ = types.Unalias(ptr.Elem())
+			}
+			if sl, ok := typ.(*types.Slice); ok {
+				typ, many = types.Unalias(sl.Elem()), true
+				if ptr, ok := typ.(*types.Pointer); ok {
+					typ = types.Unalias(ptr.Elem())
+				}
+			}
+			named, _ := typ.(*types.Named)
+			if named == nil {
+				continue
+			}
+			to := tables[naming.NamedID(named)]
+			if to == nil {
+				continue
+			}
+			fk := gormSetting(reflect.StructTag(f.StructTag), "foreignKey")
+			if many {
+				// has many: the rows of to refer to t
+				if fk == "" {
+					fk = t.Name + "_id"
+				} else {
+					fk = snake(fk)
+				}
+				relate(to, fk, t)
+				continue
+			}
+			// belongs to: t refers to a row of to
+			if fk == "" {
+				fk = snake(f.Name) + "_id"
+			} else {
+				fk = snake(fk)
+			}
+			relate(t, fk, to)
+		}
+
+		// foreign keys by convention, e.g. user_id
+		for _, c := range t.Columns {
+			if !strings.HasSuffix(c.Name, "_id") {
+				continue
+			}
+			if to := byName[strings.TrimSuffix(c.Name, "_id")]; to != nil && to != t {
+				relate(t, c.Name, to)
+			}
+		}
+	}
+	return e
+}
+
+// mapped reports whether the struct n has a field tagged with `db` or
+// `gorm`, its embedded structs included.
+func mapped(n *Node, embeds map[string][]*Node) bool {
+	if tagged(n.Fields) {
+		return true
+	}
+	for _, m := range embeds[n.ID] {
+		if m.Obj != nil && m != n && mapped(m, embeds) {
+			return true
+		}
+	}
+	return false
+}
+
+// tagged reports whether one of fs is tagged with `db` or `gorm`, the
+// fields of embedded structs outside the Graph included.
+func tagged(fs []*Field) bool {
+	for _, f := range fs {
+		tag := reflect.StructTag(f.StructTag)
+		if _, ok := tag.Lookup("db"); ok {
+			return true
+		}
+		if _, ok := tag.Lookup("gorm"); ok {
+			return true
+		}
+		if embedded := embeddedFields(f); embedded != nil && tagged(embedded) {
+			return true
+		}
+	}
+	return false
+}
+
+func isGorm(fs []*Field) bool {
+	for _, f := range fs {
+		if _, ok := reflect.StructTag(f.StructTag).Lookup("gorm"); ok {
+			return true
+		}
+		if f.Embedded && f.Obj.Pkg() != nil && f.Obj.Pkg().Path() == "gorm.io/gorm" {
+			// gorm.Model
+			return true
+		}
+	}
+	return false
+}
+
+// embeddedFields returns the fields of the struct embedded as f, when it is
+// outside the Graph, e.g. gorm.Model, or nil.
+func embeddedFields(f *Field) []*Field {
+	if !f.Embedded || f.Obj == nil {
+		return nil
+	}
+	st, ok := f.Obj.Type().Underlying().(*types.Struct)
+	if !ok {
+		return nil
+	}
+	fs := make([]*Field, 0, st.NumFields())
+	for i := 0; i < st.NumFields(); i++ {
+		v := st.Field(i)
+		fs = append(fs, &Field{
+			Name:      v.Name(),
+			Type:      naming.TypeLabel(v.Type()),
+			Exported:  v.Exported(),
+			Embedded:  v.Anonymous(),
+			StructTag: st.Tag(i),
+			Obj:       v,
+		})
+	}
+	return fs
+}
+
+// nodeColumns adds the columns of the struct n to t, the ones of the structs
+// of the Graph it embeds first.
+func nodeColumns(t *Table, n *Node, embeds map[string][]*Node, gorm bool, seen map[*Node]bool) {
+	seen[n] = true
+	for _, m := range embeds[n.ID] {
+		if m.Obj == nil || seen[m] {
+			continue
+		}
+		if _, ok := m.Obj.Type().Underlying().(*types.Struct); ok {
+			nodeColumns(t, m, embeds, gorm || isGorm(m.Fields), seen)
+		}
+	}
+	columns(t, n.Fields, gorm)
+}
+
+// columns adds the columns of fs to t, the ones of embedded structs outside
+// the Graph included. With gorm, every exported field of a basic type is a
+// column.
+func columns(t *Table, fs []*Field, gorm bool) {
+	for _, f := range fs {
+		if f.Obj == nil {
+			continue
+		}
+		tag := reflect.StructTag(f.StructTag)
+		if embedded := embeddedFields(f); embedded != nil {
+			columns(t, embedded, gorm || isGorm(embedded))
+			continue
+		}
+
+		name := ""
+		if db, ok := tag.Lookup("db"); ok {
+			name, _, _ = strings.Cut(db, ",")
+		} else if _, ok := tag.Lookup("gorm"); (ok || (gorm && f.Exported)) && !association(f.Obj.Type()) {
+			name = gormSetting(tag, "column")
+			if name == "" {
+				name = snake(f.Name)
+			}
+		}
+		if name == "" || name == "-" || tag.Get("gorm") == "-" {
+			continue
+		}
+
+		settings := gormSettings(tag)
+		_, pk := settings["primarykey"]
+		if _, ok := settings["primary_key"]; ok {
+			pk = true
+		}
+		t.Columns = append(t.Columns, &Column{
+			Name:       name,
+			Type:       f.Type,
+			PrimaryKey: pk || name == "id",
+			Nullable:   nullable(f.Obj.Type()),
+		})
+	}
+}
+
+// association reports whether typ is a struct or a slice other than a
+// column type such as time.Time or []byte.
+func association(typ types.Type) bool {
+	if ptr, ok := typ.Underlying().(*types.Pointer); ok {
+		typ = ptr.Elem()
+	}
+	switch un := typ.Underlying().(type) {
+	case *types.Slice:
+		basic, ok := un.Elem().(*types.Basic)
+		return !ok || basic.Kind() != types.Byte
+	case *types.Struct:
+		named, _ := types.Unalias(typ).(*types.Named)
+		if named == nil || named.Obj().Pkg() == nil {
+			return true
+		}
+		switch named.Obj().Pkg().Path() {
+		case "time", "database/sql", "gorm.io/gorm":
+			return false
+		}
+		return true
+	}
+	return false
+}
+
+func nullable(typ types.Type) bool {
+	if _, ok := typ.Underlying().(*types.Pointer); ok {
+		return true
+	}
+	named, _ := types.Unalias(typ).(*types.Named)
+	return named != nil && named.Obj().Pkg() != nil && named.Obj().Pkg().Path() == "database/sql" &&
+		strings.HasPrefix(named.Obj().Name(), "Null")
+}
+
+// gormSettings parses a tag like `gorm:"column:user_id;primaryKey"` into
+// lower-cased keys and their values.
+func gormSettings(tag reflect.StructTag) map[string]string {
+	settings := map[string]string{}
+	for _, s := range strings.Split(tag.Get("gorm"), ";") {
+		k, v, _ := strings.Cut(s, ":")
+		if k = strings.TrimSpace(k); k != "" {
+			settings[strings.ToLower(k)] = strings.TrimSpace(v)
+		}
+	}
+	return settings
+}
+
+func gormSetting(tag reflect.StructTag, key string) string {
+	return gormSettings(tag)[strings.ToLower(key)]
+}
+
+// snake converts a Go name to snake_case, keeping initialisms together,
+// e.g. UserID to user_id and HTTPServer to http_server.
+func snake(name string) string {
+	rs := []rune(name)
+	var b strings.Builder
+	for i, r := range rs {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(rs[i-1]) || (i+1 < len(rs) && unicode.IsLower(rs[i+1]) && unicode.IsUpper(rs[i-1]))) {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
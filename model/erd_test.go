@@ -0,0 +1,107 @@
+package model_test
+
+import (
+	"go/types"
+	"reflect"
+	"testing"
+
+	"github.com/kazukousen/gouml/model"
+)
+
+func TestBuildERD(t *testing.T) {
+	src := `
+	package db
+	import (
+		"database/sql"
+		"time"
+	)
+	type Audit struct {
+		CreatedBy string ` + "`db:\"created_by\"`" + `
+	}
+	type Company struct {
+		Audit
+		ID   int64  ` + "`db:\"id\"`" + `
+		Name string ` + "`db:\"name\"`" + `
+	}
+	type User struct {
+		UserID    int64          ` + "`gorm:\"column:uid;primaryKey\"`" + `
+		CompanyID int64
+		Company   Company
+		Nickname  sql.NullString
+		CreatedAt time.Time
+		Orders    []Order
+		cache     string
+	}
+	type Order struct {
+		ID     int64  ` + "`db:\"id\"`" + `
+		UserID int64  ` + "`db:\"user_id\"`" + `
+		Note   string ` + "`db:\"-\"`" + `
+		Secret string ` + "`db:\"secret\"`" + ` //gouml:ignore
+	}
+	type Config struct {
+		Path string ` + "`json:\"path\"`" + `
+	}
+	`
+	g := model.BuildSources([]*model.Source{source(t, src)}, model.Options{})
+	e := model.BuildERD(g)
+
+	columns := map[string][]model.Column{}
+	for _, tb := range e.Tables {
+		for _, c := range tb.Columns {
+			columns[tb.Name] = append(columns[tb.Name], *c)
+		}
+	}
+	want := map[string][]model.Column{
+		"audit": {
+			{Name: "created_by", Type: "string"},
+		},
+		"company": {
+			{Name: "created_by", Type: "string"},
+			{Name: "id", Type: "int64", PrimaryKey: true},
+			{Name: "name", Type: "string"},
+		},
+		"user": {
+			{Name: "uid", Type: "int64", PrimaryKey: true},
+			{Name: "company_id", Type: "int64", ForeignKey: "db_dCompany"},
+			{Name: "nickname", Type: "sql.NullString", Nullable: true},
+			{Name: "created_at", Type: "time.Time"},
+		},
+		"order": {
+			{Name: "id", Type: "int64", PrimaryKey: true},
+			{Name: "user_id", Type: "int64", ForeignKey: "db_dUser"},
+		},
+	}
+	if !reflect.DeepEqual(columns, want) {
+		t.Errorf("columns: got %+v, want %+v", columns, want)
+	}
+
+	relations := []model.Relation{}
+	for _, r := range e.Relations {
+		relations = append(relations, *r)
+	}
+	wantRelations := []model.Relation{
+		{From: "db_dOrder", To: "db_dUser", Column: "user_id"},
+		{From: "db_dUser", To: "db_dCompany", Column: "company_id"},
+	}
+	if !reflect.DeepEqual(relations, wantRelations) {
+		t.Errorf("relations: got %+v, want %+v", relations, wantRelations)
+	}
+
+	// the filters of the model apply to the tables
+	focused, err := model.Focus(g, "db.Order", 0, model.DirectionBoth)
+	if err != nil {
+		t.Fatalf(": %+v", err)
+	}
+	if e := model.BuildERD(focused); len(e.Tables) != 1 || e.Tables[0].Name != "order" || len(e.Relations) != 0 {
+		t.Errorf("focus: got %+v", e)
+	}
+
+	// tags are only recorded in the fields when asked
+	if tag := g.Node("db_dConfig").Fields[0].Tag; tag != "" {
+		t.Errorf("tag: got %q", tag)
+	}
+	g = model.Build([]*types.Package{check(t, src)}, model.Options{Tags: true})
+	if tag := g.Node("db_dConfig").Fields[0].Tag; tag != `json:"path"` {
+		t.Errorf("tag: got %q", tag)
+	}
+}
@@ -0,0 +1,133 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Direction is the direction the edges are followed in by Focus.
+type Direction string
+
+// Directions of Focus.
+const (
+	// DirectionOut follows the edges from the nodes, to the types they depend on.
+	DirectionOut Direction = "out"
+	// DirectionIn follows the edges to the nodes, to the types depending on them.
+	DirectionIn Direction = "in"
+	// DirectionBoth follows the edges either way.
+	DirectionBoth Direction = "both"
+)
+
+// Focus returns the subgraph of g made of the node named name and the nodes
+// reachable from it in at most depth edges followed in dir. The name is the
+// type name qualified by the import path or the name of its package, e.g.
+// github.com/org/svc/domain.User or domain.User.
+func Focus(g *Graph, name string, depth int, dir Direction) (*Graph, error) {
+	if err := CheckFocus(name, depth, dir); err != nil {
+		return nil, err
+	}
+	start, err := lookup(g, name)
+	if err != nil {
+		return nil, err
+	}
+
+	next := map[string][]string{}
+	for _, e := range g.Edges {
+		if dir != DirectionIn {
+			next[e.From] = append(next[e.From], e.To)
+		}
+		if dir != DirectionOut {
+			next[e.To] = append(next[e.To], e.From)
+		}
+	}
+	kept := map[string]struct{}{start.ID: {}}
+	frontier := []string{start.ID}
+	for i := 0; i < depth && len(frontier) > 0; i++ {
+		reached := []string{}
+		for _, id := range frontier {
+			for _, to := range next[id] {
+				if _, ok := kept[to]; ok {
+					continue
+				}
+				kept[to] = struct{}{}
+				reached = append(reached, to)
+			}
+		}
+		frontier = reached
+	}
+
+	sub := NewGraph()
+	pkgs := map[*Package]struct{}{}
+	for _, n := range g.Nodes {
+		if _, ok := kept[n.ID]; ok {
+			sub.AddNode(n)
+			pkgs[n.Package] = struct{}{}
+		}
+	}
+	for _, pkg := range g.Packages {
+		if _, ok := pkgs[pkg]; ok {
+			sub.AddPackage(pkg)
+		}
+	}
+	for _, e := range g.Edges {
+		_, from := kept[e.From]
+		_, to := kept[e.To]
+		if from && to {
+			sub.AddEdge(e)
+		}
+	}
+	for _, n := range g.Notes {
+		if _, ok := kept[n.Node]; ok {
+			sub.AddNote(n)
+		}
+	}
+	return sub, nil
+}
+
+// CheckFocus reports the arguments of Focus which are wrong whatever the
+// Graph, so that they fail before the packages are loaded.
+func CheckFocus(name string, depth int, dir Direction) error {
+	switch dir {
+	case DirectionOut, DirectionIn, DirectionBoth:
+	default:
+		return fmt.Errorf("unknown direction: %s, one of out, in or both", dir)
+	}
+	if depth < 0 {
+		return fmt.Errorf("focus %s: negative depth %d", name, depth)
+	}
+	if i := strings.LastIndex(name, "."); i <= 0 || i == len(name)-1 {
+		return fmt.Errorf("focus %s: not a type qualified by a package, e.g. domain.User", name)
+	}
+	return nil
+}
+
+// lookup returns the node of a type named like pkg.Type.
+func lookup(g *Graph, name string) (*Node, error) {
+	i := strings.LastIndex(name, ".")
+	pkg, typ := name[:i], name[i+1:]
+	found := []*Node{}
+	loaded := false
+	for _, n := range g.Nodes {
+		if n.Package.Path != pkg && n.Package.Name != pkg {
+			continue
+		}
+		loaded = true
+		if n.Obj != nil && n.Name == typ {
+			found = append(found, n)
+		}
+	}
+	switch len(found) {
+	case 0:
+		if !loaded {
+			return nil, fmt.Errorf("focus %s: no type %s, package %s is not loaded", name, typ, pkg)
+		}
+		return nil, fmt.Errorf("focus %s: no type %s in package %s", name, typ, pkg)
+	case 1:
+		return found[0], nil
+	}
+	paths := make([]string, 0, len(found))
+	for _, n := range found {
+		paths = append(paths, n.Package.Path+"."+n.Name)
+	}
+	return nil, fmt.Errorf("focus %s: ambiguous, one of %s", name, strings.Join(paths, ", "))
+}
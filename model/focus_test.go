@@ -0,0 +1,77 @@
+package model_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kazukousen/gouml/model"
+)
+
+func TestFocus(t *testing.T) {
+	src := `
+	package shop
+	type Item struct{ Price Money }
+	type Money struct{ Currency Currency }
+	type Currency string
+	type Cart struct{ Items []Item }
+	type Checkout struct{ Cart *Cart }
+	type Unrelated struct{}
+	`
+	g := model.BuildSources([]*model.Source{source(t, src)}, model.Options{})
+
+	tests := []struct {
+		name  string
+		depth int
+		dir   model.Direction
+		want  []string
+	}{
+		{"shop.Item", 0, model.DirectionBoth, []string{"Item"}},
+		{"shop.Item", 1, model.DirectionOut, []string{"Item", "Money"}},
+		{"shop.Item", 2, model.DirectionOut, []string{"Currency", "Item", "Money"}},
+		{"shop.Item", 2, model.DirectionIn, []string{"Cart", "Checkout", "Item"}},
+		{"shop.Item", 1, model.DirectionBoth, []string{"Cart", "Item", "Money"}},
+	}
+	for _, tt := range tests {
+		sub, err := model.Focus(g, tt.name, tt.depth, tt.dir)
+		if err != nil {
+			t.Fatalf(": %+v", err)
+		}
+		got := []string{}
+		for _, n := range sub.Nodes {
+			got = append(got, n.Name)
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("%d %s: got %v, want %v", tt.depth, tt.dir, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%d %s: got %v, want %v", tt.depth, tt.dir, got, tt.want)
+				break
+			}
+		}
+		for _, e := range sub.Edges {
+			if sub.Node(e.From) == nil || sub.Node(e.To) == nil {
+				t.Errorf("%d %s: dangling edge %s -> %s", tt.depth, tt.dir, e.From, e.To)
+			}
+		}
+	}
+
+	if _, err := model.Focus(g, "shop.Order", 1, model.DirectionBoth); err == nil || !strings.Contains(err.Error(), "no type Order in package shop") {
+		t.Errorf("unknown type: got %v", err)
+	}
+	for _, tt := range []struct {
+		name  string
+		depth int
+		dir   model.Direction
+	}{
+		{"Order", 1, model.DirectionBoth},
+		{"shop.", 1, model.DirectionBoth},
+		{"shop.Cart", -1, model.DirectionBoth},
+		{"shop.Cart", 1, "up"},
+	} {
+		if err := model.CheckFocus(tt.name, tt.depth, tt.dir); err == nil {
+			t.Errorf("%s %d %s: no error", tt.name, tt.depth, tt.dir)
+		}
+	}
+}
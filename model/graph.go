@@ -0,0 +1,315 @@
+// Package model holds the diagram analysed from Go packages, independent of any
+// output format. Renderers only consume a Graph, so formats, filters and
+// transformations can be added without touching the analysis.
+package model
+
+import (
+	"go/types"
+	"strings"
+)
+
+// Graph is the analysed diagram: packages, their nodes, the edges between
+// the nodes and the notes attached to them.
+type Graph struct {
+	Packages []*Package
+	Nodes    []*Node
+	Edges    []*Edge
+	Notes    []*Note
+
+	nodes map[string]*Node
+}
+
+// NewGraph ...
+func NewGraph() *Graph {
+	return &Graph{
+		Packages: []*Package{},
+		Nodes:    []*Node{},
+		Edges:    []*Edge{},
+		Notes:    []*Note{},
+		nodes:    map[string]*Node{},
+	}
+}
+
+// AddPackage ...
+func (g *Graph) AddPackage(pkg *Package) {
+	g.Packages = append(g.Packages, pkg)
+}
+
+// AddNode adds n, which can then be looked up with Node.
+func (g *Graph) AddNode(n *Node) {
+	g.Nodes = append(g.Nodes, n)
+	g.nodes[n.ID] = n
+}
+
+// AddEdge ...
+func (g *Graph) AddEdge(e *Edge) {
+	g.Edges = append(g.Edges, e)
+}
+
+// AddNote ...
+func (g *Graph) AddNote(n *Note) {
+	g.Notes = append(g.Notes, n)
+}
+
+// Node returns the node identified by id, or nil.
+func (g *Graph) Node(id string) *Node {
+	return g.nodes[id]
+}
+
+// NodesOf returns the nodes declared in pkg, in the order they were added.
+func (g *Graph) NodesOf(pkg *Package) []*Node {
+	nodes := []*Node{}
+	for _, n := range g.Nodes {
+		if n.Package == pkg {
+			nodes = append(nodes, n)
+		}
+	}
+	return nodes
+}
+
+// NotesOf returns the notes placed in pkg, in the order they were added.
+func (g *Graph) NotesOf(pkg *Package) []*Note {
+	notes := []*Note{}
+	for _, n := range g.Notes {
+		if n.Package == pkg {
+			notes = append(notes, n)
+		}
+	}
+	return notes
+}
+
+// Package is a loaded Go package.
+type Package struct {
+	// Path is the import path, which identifies the package.
+	Path string
+	Name string
+	// Module is the module path, empty for the standard library or when
+	// unknown.
+	Module string
+	// Imports are the imported packages, sorted by import path.
+	Imports []*Import
+}
+
+// Import is a package imported by a Package.
+type Import struct {
+	Path string
+	// Module is the module path, empty for the standard library or when
+	// unknown.
+	Module string
+}
+
+// Kind classifies a Node.
+type Kind string
+
+// Kinds of a Node.
+const (
+	KindInterface   Kind = "interface"
+	KindEntity      Kind = "entity"
+	KindValueObject Kind = "value_object"
+	// KindClass is a type with neither the stereotype of an entity nor the
+	// one of a value object.
+	KindClass Kind = "class"
+	// KindConstraint is an interface which can only be used as a type constraint.
+	KindConstraint Kind = "constraint"
+	// KindAlias is a type alias, pointing at the aliased type with an EdgeAlias.
+	KindAlias Kind = "alias"
+	// KindUtility gathers the package-level functions of a package which
+	// construct no type of it.
+	KindUtility Kind = "utility"
+	// KindGlobals gathers the package-level variables of a package as fields.
+	KindGlobals Kind = "globals"
+	// KindEnum is a type with declared constants, listed as its Constants.
+	KindEnum Kind = "enum"
+)
+
+// Node is a declared type, or the utility or globals node of a package.
+type Node struct {
+	// ID is unique in the Graph and derived from the import path and the name.
+	ID      string
+	Name    string
+	Package *Package
+	Kind    Kind
+	// TypeParams of a generic type.
+	TypeParams []*TypeParam
+	Fields     []*Field
+	Methods    []*Method
+	// Terms are the embedded elements of a constraint, e.g. `~int | ~float64`.
+	Terms []string
+	// Aliased is the label of the aliased type of an alias.
+	Aliased string
+	// Constants of an enum, in declaration order.
+	Constants []*Constant
+	// Doc is the doc comment, recorded with Options.Docs.
+	Doc string
+	// Stereotype replaces the stereotype of Kind when not empty.
+	Stereotype string
+	// Color is the fill color, e.g. #FFAA00, when not empty.
+	Color string
+	// Role is the role recognised with Options.Roles, if any.
+	Role Role
+	// Layer groups the node with the others of the same layer when not empty.
+	Layer string
+	// Stub is true for a collapsed node of another package in a part of a
+	// split Graph, without members.
+	Stub bool
+	// Link is the location of the diagram the node is drawn in, when not empty.
+	Link string
+
+	// Obj is the analysed object, for consumers that need more than the model.
+	// It is nil for a utility or globals node.
+	Obj *types.TypeName
+}
+
+// Title returns the name followed by the type parameters, e.g. `Cache[K comparable, V any]`.
+func (n *Node) Title() string {
+	if len(n.TypeParams) == 0 {
+		return n.Name
+	}
+	var b strings.Builder
+	b.WriteString(n.Name)
+	b.WriteString("[")
+	for i, tp := range n.TypeParams {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(tp.Name)
+		b.WriteString(" ")
+		b.WriteString(tp.Constraint)
+	}
+	b.WriteString("]")
+	return b.String()
+}
+
+// TypeParam is a type parameter of a generic type.
+type TypeParam struct {
+	Name       string
+	Constraint string
+}
+
+// Field is a field of a struct type.
+// An embedded type in the Graph is an EdgeEmbed instead.
+type Field struct {
+	Name     string
+	Type     string
+	Exported bool
+	// Embedded is true for an embedded type outside the Graph, e.g. sync.Mutex.
+	Embedded bool
+	// Tag is the struct tag, e.g. `json:"id"`, recorded with Options.Tags.
+	Tag string
+	// Doc is the comment on one line, recorded with Options.Docs.
+	Doc string
+	// StructTag is the struct tag, recorded whatever Options.Tags.
+	StructTag string
+
+	// Obj is the analysed field, or variable of a globals node, for consumers
+	// that need more than the model.
+	Obj *types.Var
+}
+
+// Method is a method of a type, the signature of a function type, or a
+// package-level function.
+type Method struct {
+	Name     string
+	Exported bool
+	// Static is true for a constructor, a package-level function returning the
+	// type it is listed in.
+	Static  bool
+	Params  []*Param
+	Results []*Param
+	// PromotedFrom is the label of the embedded field or interface the method
+	// is promoted from. It is empty for an explicit method.
+	PromotedFrom string
+	// Doc is the comment on one line, recorded with Options.Docs.
+	Doc string
+}
+
+// Constant is a constant declared with the type of an enum.
+type Constant struct {
+	// Name is qualified by the package name when declared in another package.
+	Name  string
+	Value string
+	// Doc is the doc comment, or else the line comment, on one line.
+	Doc string
+}
+
+// Param is a parameter or a result of a Method. Name may be empty.
+type Param struct {
+	Name string
+	Type string
+}
+
+// EdgeKind classifies an Edge.
+type EdgeKind string
+
+// Kinds of an Edge.
+const (
+	// EdgeField means From has a field of type To.
+	EdgeField EdgeKind = "field"
+	// EdgeUse means an exported method of From takes To as a parameter.
+	EdgeUse EdgeKind = "use"
+	// EdgeReturn means an exported method of From returns To.
+	EdgeReturn EdgeKind = "return"
+	// EdgeWrap means From is a slice or a map of To.
+	EdgeWrap EdgeKind = "wrap"
+	// EdgeImplements means From implements the interface To.
+	EdgeImplements EdgeKind = "implements"
+	// EdgeEmbed means From is a struct embedding To.
+	EdgeEmbed EdgeKind = "embed"
+	// EdgeExtend means From is an interface embedding the interface To.
+	EdgeExtend EdgeKind = "extend"
+	// EdgeAlias means From is an alias of To.
+	EdgeAlias EdgeKind = "alias"
+	// EdgeConstraint means a type parameter of From is constrained by To.
+	EdgeConstraint EdgeKind = "constraint"
+	// EdgeTypeArgument means From refers to an instantiation with the type argument To.
+	EdgeTypeArgument EdgeKind = "type_argument"
+)
+
+// Multiplicity is how many To an Edge refers to. It is empty when it does not apply.
+type Multiplicity string
+
+// Multiplicities of an Edge.
+const (
+	MultiplicityOne      Multiplicity = "1"
+	MultiplicityOptional Multiplicity = "0..1"
+	MultiplicityMany     Multiplicity = "*"
+)
+
+// Edge is a directed relation between two nodes, referenced by ID.
+type Edge struct {
+	From         string
+	To           string
+	Kind         EdgeKind
+	Multiplicity Multiplicity
+}
+
+// NoteKind classifies a Note.
+type NoteKind string
+
+// Kinds of a Note.
+const (
+	// NoteConstants lists the constants of the type Node, with
+	// Options.ConstantNotes.
+	NoteConstants NoteKind = "constants"
+	// NoteErrors lists the sentinel errors the function or method Member of
+	// Node returns.
+	NoteErrors NoteKind = "errors"
+	// NoteDoc is the doc comment of the type Node, with Options.Docs.
+	NoteDoc NoteKind = "doc"
+	// NoteText is a free text attached to Node with //gouml:note.
+	NoteText NoteKind = "text"
+)
+
+// Note is a text attached to a node.
+type Note struct {
+	// Package is where the note is placed.
+	Package *Package
+	// Node is the ID of the node the note is attached to. It may not be in the Graph.
+	Node string
+	Kind NoteKind
+	// Member is the name of the function or method of Node the note is about.
+	// It is empty for a note about the node itself.
+	Member string
+	Title  string
+	Lines  []string
+}
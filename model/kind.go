@@ -0,0 +1,36 @@
+package model
+
+import (
+	"go/types"
+)
+
+// isSentinel reports whether v is a package-level error variable, such as
+// ErrNotFound or io.EOF, meant to be compared with errors.Is.
+func isSentinel(v *types.Var) bool {
+	if v.Pkg() == nil || v.Parent() != v.Pkg().Scope() {
+		return false
+	}
+	errType := types.Universe.Lookup("error").Type()
+	return types.Implements(v.Type(), errType.Underlying().(*types.Interface))
+}
+
+// isCommand reports whether f is a pointer-receiver method returning nothing
+// or only an error, which changes the state of its receiver.
+func isCommand(f *types.Func) bool {
+	// *types.Func.Type() is always a *types.Signature
+	sig := f.Type().(*types.Signature)
+	if _, ok := sig.Recv().Type().(*types.Pointer); !ok {
+		return false
+	}
+	if sig.Results().Len() == 0 {
+		return true
+	}
+	if sig.Results().Len() == 1 {
+		t := sig.Results().At(0).Type()
+		errType := types.Universe.Lookup("error").Type()
+		if types.Implements(t, errType.Underlying().(*types.Interface)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,204 @@
+package model
+
+import (
+	"go/types"
+	"strings"
+)
+
+// Role is the architectural role of a node, recognised from its shape with
+// Options.Roles.
+type Role string
+
+// Roles of a Node.
+const (
+	// RoleRepository is an interface whose methods are CRUD-like on one entity
+	// type, or a type implementing one.
+	RoleRepository Role = "repository"
+	// RoleService is a type whose exported methods take a context.Context and
+	// return an error.
+	RoleService Role = "service"
+	// RoleFactory gathers the package-level functions of a package returning
+	// an interface.
+	RoleFactory Role = "factory"
+)
+
+// layers are the groups of the roles with Options.Layers.
+var layers = map[Role]string{
+	RoleRepository: "repositories",
+	RoleService:    "services",
+	RoleFactory:    "factories",
+}
+
+// crud are the prefixes of the methods of a repository.
+var crud = []string{
+	"Get", "Find", "List", "Load", "Fetch", "Search", "Count", "Exists",
+	"Create", "Insert", "Add", "Save", "Store", "Put", "Update", "Upsert",
+	"Delete", "Remove",
+}
+
+// role returns the role of the type declared by obj, or the empty Role.
+func (b *builder) role(obj *types.TypeName) Role {
+	if obj.IsAlias() || isGeneric(obj.Type()) {
+		return ""
+	}
+	if iface, ok := obj.Type().Underlying().(*types.Interface); ok {
+		if !iface.IsMethodSet() || iface.NumMethods() == 0 {
+			return ""
+		}
+		if b.repository(iface) {
+			return RoleRepository
+		}
+		if service(iface.Method, iface.NumMethods()) {
+			return RoleService
+		}
+		return ""
+	}
+	if named, ok := obj.Type().(*types.Named); ok && service(named.Method, named.NumMethods()) {
+		return RoleService
+	}
+	return ""
+}
+
+// repository reports whether every method of iface has a CRUD-like name and
+// the structs of the loaded packages in their signatures are one and the same.
+func (b *builder) repository(iface *types.Interface) bool {
+	if iface.NumMethods() < 2 {
+		return false
+	}
+	entities := map[string]struct{}{}
+	for i := 0; i < iface.NumMethods(); i++ {
+		f := iface.Method(i)
+		if !isCRUD(f.Name()) {
+			return false
+		}
+		sig := f.Type().(*types.Signature)
+		for _, tuple := range []*types.Tuple{sig.Params(), sig.Results()} {
+			for j := 0; j < tuple.Len(); j++ {
+				// identifiers such as `type UserID string` are no entities
+				typ, _ := b.elem(tuple.At(j).Type())
+				id := b.target(typ)
+				if _, ok := typ.Underlying().(*types.Struct); id == "" || !ok {
+					continue
+				}
+				entities[id] = struct{}{}
+			}
+		}
+	}
+	return len(entities) == 1
+}
+
+func isCRUD(name string) bool {
+	for _, prefix := range crud {
+		if name == prefix {
+			return true
+		}
+		rest := strings.TrimPrefix(name, prefix)
+		if rest != name && rest[0] >= 'A' && rest[0] <= 'Z' {
+			return true
+		}
+	}
+	return false
+}
+
+// service reports whether the exported ones of the n methods given by at
+// take a context.Context first and return an error last. There must be one
+// at least.
+func service(at func(int) *types.Func, n int) bool {
+	exported := 0
+	for i := 0; i < n; i++ {
+		f := at(i)
+		if !f.Exported() {
+			continue
+		}
+		exported++
+		sig := f.Type().(*types.Signature)
+		if sig.Params().Len() == 0 || !isContext(sig.Params().At(0).Type()) {
+			return false
+		}
+		if sig.Results().Len() == 0 || !isError(sig.Results().At(sig.Results().Len()-1).Type()) {
+			return false
+		}
+	}
+	return exported > 0
+}
+
+// isFactory reports whether sig returns an interface other than error first.
+func isFactory(sig *types.Signature) bool {
+	if sig.Results().Len() == 0 {
+		return false
+	}
+	typ := sig.Results().At(0).Type()
+	if _, ok := typ.(*types.TypeParam); ok {
+		return false
+	}
+	return types.IsInterface(typ) && !isError(typ)
+}
+
+func isContext(typ types.Type) bool {
+	named, _ := types.Unalias(typ).(*types.Named)
+	return named != nil && named.Obj().Pkg() != nil && named.Obj().Pkg().Path() == "context" &&
+		named.Obj().Name() == "Context"
+}
+
+func isError(typ types.Type) bool {
+	return types.Identical(typ, types.Universe.Lookup("error").Type())
+}
+
+// layerOf returns the layer of the role r with Options.Layers.
+func (b *builder) layerOf(r Role) string {
+	if !b.opts.Layers {
+		return ""
+	}
+	return layers[r]
+}
+
+// implementations gives the role of a repository to its implementations,
+// which look like services, and puts the nodes implementing an interface of
+// a layer in that layer.
+func (b *builder) implementations() {
+	nodes := map[string]*Node{}
+	for _, n := range b.graph.Nodes {
+		nodes[n.ID] = n
+	}
+	for _, e := range b.graph.Edges {
+		if e.Kind != EdgeImplements {
+			continue
+		}
+		from, to := nodes[e.From], nodes[e.To]
+		if to.Role == RoleRepository && from.Role != RoleRepository {
+			from.Role = RoleRepository
+			from.Layer = b.layerOf(from.Role)
+		}
+		if from.Layer == "" {
+			from.Layer = to.Layer
+		}
+	}
+}
+
+// Layer is a group of the nodes of a package with the same Node.Layer.
+type Layer struct {
+	Name  string
+	Nodes []*Node
+}
+
+// ByLayer splits nodes into the ones in no layer and the layers, in order of
+// appearance.
+func ByLayer(nodes []*Node) ([]*Node, []*Layer) {
+	rest := []*Node{}
+	ls := []*Layer{}
+	byName := map[string]*Layer{}
+	for _, n := range nodes {
+		if n.Layer == "" {
+			rest = append(rest, n)
+			continue
+		}
+		l, ok := byName[n.Layer]
+		if !ok {
+			l = &Layer{Name: n.Layer}
+			byName[n.Layer] = l
+			ls = append(ls, l)
+		}
+		l.Nodes = append(l.Nodes, n)
+	}
+	return rest, ls
+}
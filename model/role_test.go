@@ -0,0 +1,68 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/kazukousen/gouml/model"
+)
+
+func TestBuildRoles(t *testing.T) {
+	src := `
+	package app
+	import "context"
+	type UserID string
+	type User struct{}
+	type Order struct{}
+	type UserRepository interface {
+		FindByID(ctx context.Context, id UserID) (*User, error)
+		List(ctx context.Context) ([]*User, error)
+		Save(ctx context.Context, u *User) error
+	}
+	type Mixed interface {
+		FindUser(id UserID) (*User, error)
+		FindOrder(id string) (*Order, error)
+	}
+	type userRepository struct{}
+	func (r *userRepository) FindByID(ctx context.Context, id UserID) (*User, error) { return nil, nil }
+	func (r *userRepository) List(ctx context.Context) ([]*User, error) { return nil, nil }
+	func (r *userRepository) Save(ctx context.Context, u *User) error { return nil }
+	type SignUp struct{}
+	func (s *SignUp) Run(ctx context.Context, name string) (*User, error) { return nil, nil }
+	func (s *SignUp) validate(name string) bool { return true }
+	func NewUserRepository() UserRepository { return &userRepository{} }
+	func Helper() {}
+	`
+	g := model.BuildSources([]*model.Source{source(t, src)}, model.Options{Layers: true})
+
+	tests := []struct {
+		id    string
+		role  model.Role
+		layer string
+	}{
+		{"app_dUserRepository", model.RoleRepository, "repositories"},
+		{"app_dMixed", "", ""},
+		{"app_duserRepository", model.RoleRepository, "repositories"},
+		{"app_dSignUp", model.RoleService, "services"},
+		{"app_dUser", "", ""},
+		{"app_x0023factories", model.RoleFactory, "factories"},
+	}
+	for _, tt := range tests {
+		n := g.Node(tt.id)
+		if n == nil {
+			t.Errorf("%s: not found", tt.id)
+			continue
+		}
+		if n.Role != tt.role || n.Layer != tt.layer {
+			t.Errorf("%s: got %q in %q, want %q in %q", tt.id, n.Role, n.Layer, tt.role, tt.layer)
+		}
+	}
+	if n := g.Node("app_x0023functions"); n == nil || len(n.Methods) != 1 || n.Methods[0].Name != "Helper" {
+		t.Errorf("functions: got %+v", n)
+	}
+
+	// without the option the roles are not recognised
+	g = model.BuildSources([]*model.Source{source(t, src)}, model.Options{})
+	if r := g.Node("app_dUserRepository").Role; r != "" {
+		t.Errorf("no roles: got %q", r)
+	}
+}
@@ -0,0 +1,28 @@
+package model
+
+import (
+	"go/ast"
+	"go/types"
+)
+
+// Source is a type-checked package together with its syntax.
+// Files and Info are optional: without them, the analysis of function bodies
+// is left out.
+type Source struct {
+	Types *types.Package
+	Files []*ast.File
+	// Info records at least the Defs and Uses of Files.
+	Info *types.Info
+	// Modules holds the module paths of the package and of its imports by
+	// import path, when the loader knows them.
+	Modules map[string]string
+}
+
+// Sources wraps pkgs without their syntax.
+func Sources(pkgs []*types.Package) []*Source {
+	srcs := make([]*Source, 0, len(pkgs))
+	for _, pkg := range pkgs {
+		srcs = append(srcs, &Source{Types: pkg})
+	}
+	return srcs
+}
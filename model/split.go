@@ -0,0 +1,70 @@
+package model
+
+// Part is the diagram of one package of a split Graph.
+type Part struct {
+	Package *Package
+	Graph   *Graph
+}
+
+// Split splits g into one Graph per package with nodes. A part holds the
+// nodes and the notes of its package, and the edges from and to them. The
+// nodes of other packages at the other end of the edges are collapsed into
+// stubs, linked to the part of their package by link.
+func Split(g *Graph, link func(*Package) string) []*Part {
+	parts := []*Part{}
+	for _, pkg := range g.Packages {
+		nodes := g.NodesOf(pkg)
+		if len(nodes) == 0 {
+			continue
+		}
+		sub := NewGraph()
+		for _, n := range nodes {
+			sub.AddNode(n)
+		}
+		stubs := map[*Package][]*Node{}
+		stub := func(id string) {
+			if sub.Node(id) != nil {
+				return
+			}
+			n := g.Node(id)
+			s := &Node{
+				ID:         n.ID,
+				Name:       n.Name,
+				Package:    n.Package,
+				Kind:       n.Kind,
+				TypeParams: n.TypeParams,
+				Fields:     []*Field{},
+				Methods:    []*Method{},
+				Terms:      []string{},
+				Constants:  []*Constant{},
+				Stereotype: n.Stereotype,
+				Color:      n.Color,
+				Role:       n.Role,
+				Stub:       true,
+				Link:       link(n.Package),
+				Obj:        n.Obj,
+			}
+			sub.AddNode(s)
+			stubs[s.Package] = append(stubs[s.Package], s)
+		}
+		for _, e := range g.Edges {
+			from, to := g.Node(e.From), g.Node(e.To)
+			if from.Package != pkg && to.Package != pkg {
+				continue
+			}
+			stub(e.From)
+			stub(e.To)
+			sub.AddEdge(e)
+		}
+		for _, p := range g.Packages {
+			if p == pkg || len(stubs[p]) > 0 {
+				sub.AddPackage(p)
+			}
+		}
+		for _, n := range g.NotesOf(pkg) {
+			sub.AddNote(n)
+		}
+		parts = append(parts, &Part{Package: pkg, Graph: sub})
+	}
+	return parts
+}
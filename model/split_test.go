@@ -0,0 +1,50 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/kazukousen/gouml/model"
+)
+
+func TestSplit(t *testing.T) {
+	g := model.NewGraph()
+	domain := &model.Package{Path: "svc/domain", Name: "domain"}
+	app := &model.Package{Path: "svc/app", Name: "app"}
+	empty := &model.Package{Path: "svc/empty", Name: "empty"}
+	g.AddPackage(app)
+	g.AddPackage(domain)
+	g.AddPackage(empty)
+	g.AddNode(&model.Node{ID: "app_dService", Name: "Service", Package: app, Kind: model.KindEntity, Fields: []*model.Field{{Name: "users", Type: "domain.Users"}}})
+	g.AddNode(&model.Node{ID: "domain_dUser", Name: "User", Package: domain, Kind: model.KindEntity, Fields: []*model.Field{{Name: "Name", Type: "string"}}})
+	g.AddNode(&model.Node{ID: "domain_dUsers", Name: "Users", Package: domain, Kind: model.KindInterface})
+	g.AddEdge(&model.Edge{From: "app_dService", To: "domain_dUsers", Kind: model.EdgeField})
+	g.AddEdge(&model.Edge{From: "domain_dUsers", To: "domain_dUser", Kind: model.EdgeReturn})
+	g.AddNote(&model.Note{Package: domain, Node: "domain_dUser", Kind: model.NoteText, Lines: []string{"a user"}})
+
+	parts := model.Split(g, func(pkg *model.Package) string { return pkg.Name + ".svg" })
+	if len(parts) != 2 || parts[0].Package != app || parts[1].Package != domain {
+		t.Fatalf("got %d parts", len(parts))
+	}
+
+	a := parts[0].Graph
+	if len(a.Packages) != 2 || len(a.Nodes) != 2 || len(a.Edges) != 1 || len(a.Notes) != 0 {
+		t.Errorf("app: got %d packages, %d nodes, %d edges, %d notes", len(a.Packages), len(a.Nodes), len(a.Edges), len(a.Notes))
+	}
+	if n := a.Node("app_dService"); n.Stub || len(n.Fields) != 1 {
+		t.Errorf("app: Service is collapsed")
+	}
+	if n := a.Node("domain_dUsers"); !n.Stub || n.Link != "domain.svg" || n.Kind != model.KindInterface {
+		t.Errorf("app: got stub %+v", n)
+	}
+
+	d := parts[1].Graph
+	if len(d.Packages) != 2 || len(d.Nodes) != 3 || len(d.Edges) != 2 || len(d.Notes) != 1 {
+		t.Errorf("domain: got %d packages, %d nodes, %d edges, %d notes", len(d.Packages), len(d.Nodes), len(d.Edges), len(d.Notes))
+	}
+	if n := d.Node("app_dService"); !n.Stub || len(n.Fields) != 0 || n.Link != "app.svg" {
+		t.Errorf("domain: got stub %+v", n)
+	}
+	if n := d.Node("domain_dUser"); n.Stub || len(n.Fields) != 1 {
+		t.Errorf("domain: User is collapsed")
+	}
+}
@@ -1,12 +1,83 @@
 package gouml
 
 import (
-	"bytes"
-	"go/types"
+	"io"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/kazukousen/gouml/model"
 )
 
 // Parser ...
 type Parser interface {
-	Build(pkgs []*types.Package)
-	WriteTo(buf *bytes.Buffer)
+	Build(srcs []*model.Source) error
+	// WriteTo writes the diagram of the packages built to w.
+	WriteTo(w io.Writer) (int64, error)
+}
+
+// Renderer writes a model.Graph in an output format.
+type Renderer interface {
+	// Render writes g to w as it goes, and returns the number of bytes
+	// written.
+	Render(w io.Writer, g *model.Graph) (int64, error)
+}
+
+// Options ...
+type Options struct {
+	model.Options
+	// External draws the modules imported from outside of the loaded
+	// packages in the package diagram.
+	External bool
+	// Focus restricts the diagram to the type named like pkg.Type and its
+	// neighbors within Depth edges followed in Direction, when not empty.
+	Focus     string
+	Depth     int
+	Direction model.Direction
+}
+
+// NewParser returns a Parser which analyses packages into a model.Graph and writes it with renderer.
+func NewParser(logger log.Logger, renderer Renderer, opts Options) Parser {
+	return &parser{
+		logger:   log.With(logger, "component", "parser"),
+		renderer: renderer,
+		opts:     opts,
+		graph:    model.NewGraph(),
+	}
+}
+
+type parser struct {
+	logger   log.Logger
+	renderer Renderer
+	opts     Options
+	graph    *model.Graph
+}
+
+func (p *parser) Build(srcs []*model.Source) error {
+	start := time.Now()
+	defer func() {
+		elapsed := time.Since(start)
+		level.Debug(p.logger).Log("msg", "built model", "ms", elapsed.Truncate(time.Millisecond))
+	}()
+
+	p.graph = model.BuildSources(srcs, p.opts.Options)
+	if p.opts.Focus == "" {
+		return nil
+	}
+	g, err := model.Focus(p.graph, p.opts.Focus, p.opts.Depth, p.opts.Direction)
+	if err != nil {
+		return err
+	}
+	p.graph = g
+	return nil
+}
+
+func (p parser) WriteTo(w io.Writer) (int64, error) {
+	start := time.Now()
+	defer func() {
+		elapsed := time.Since(start)
+		level.Debug(p.logger).Log("msg", "write to file", "ms", elapsed.Truncate(time.Millisecond))
+	}()
+
+	return p.renderer.Render(w, p.graph)
 }
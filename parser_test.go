@@ -0,0 +1,52 @@
+package gouml_test
+
+import (
+	"errors"
+	"go/types"
+	"strings"
+	"testing"
+
+	"github.com/go-kit/kit/log"
+	"github.com/kazukousen/gouml"
+	"github.com/kazukousen/gouml/model"
+)
+
+func TestParserWriteTo(t *testing.T) {
+	pkg := types.NewPackage("shop", "shop")
+	obj := types.NewTypeName(0, pkg, "Item", nil)
+	types.NewNamed(obj, types.NewStruct(nil, nil), nil)
+	pkg.Scope().Insert(obj)
+
+	p := gouml.PlantUMLParser(log.NewNopLogger(), gouml.Options{})
+	if err := p.Build(model.Sources([]*types.Package{pkg})); err != nil {
+		t.Fatalf(": %+v", err)
+	}
+	w := &strings.Builder{}
+	n, err := p.WriteTo(w)
+	if err != nil {
+		t.Fatalf(": %+v", err)
+	}
+	if n != int64(w.Len()) {
+		t.Errorf("got %d bytes, wrote %d", n, w.Len())
+	}
+	if !strings.Contains(w.String(), `class "Item" as shop_dItem`) {
+		t.Errorf("got %s", w.String())
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestParserWriteToError(t *testing.T) {
+	pkg := types.NewPackage("shop", "shop")
+	p := gouml.PlantUMLParser(log.NewNopLogger(), gouml.Options{})
+	if err := p.Build(model.Sources([]*types.Package{pkg})); err != nil {
+		t.Fatalf(": %+v", err)
+	}
+	if _, err := p.WriteTo(failingWriter{}); err == nil || err.Error() != "disk full" {
+		t.Errorf("got %v", err)
+	}
+}
@@ -6,6 +6,6 @@ import (
 )
 
 // PlantUMLParser ...
-func PlantUMLParser(logger log.Logger) Parser {
-	return plantuml.NewParser(logger)
+func PlantUMLParser(logger log.Logger, opts Options) Parser {
+	return NewParser(logger, plantuml.NewRenderer(), opts)
 }
@@ -0,0 +1,185 @@
+// Package schema defines the machine-readable model written by `gouml export`.
+//
+// A Document is versioned by Version. Within a version fields and enumeration
+// values are only ever added; renaming or removing one bumps the version.
+package schema
+
+// Version of the schema produced by this package.
+const Version = "1"
+
+// Document is the root object of an export.
+type Document struct {
+	// Version is always the Version constant of the producing gouml.
+	Version   string     `json:"version"`
+	Packages  []Package  `json:"packages"`
+	Models    []Model    `json:"models"`
+	Relations []Relation `json:"relations"`
+	Notes     []Note     `json:"notes"`
+}
+
+// Package is a loaded Go package.
+type Package struct {
+	// Path is the import path, which identifies the package.
+	Path string `json:"path"`
+	Name string `json:"name"`
+	// Module is the module path, empty for the standard library or when unknown.
+	Module string `json:"module,omitempty"`
+}
+
+// Kind classifies a Model.
+type Kind string
+
+// Kinds of a Model.
+const (
+	KindInterface   Kind = "interface"
+	KindEntity      Kind = "entity"
+	KindValueObject Kind = "value_object"
+	// KindClass is a type with neither the stereotype of an entity nor the
+	// one of a value object.
+	KindClass Kind = "class"
+	// KindConstraint is an interface which can only be used as a type constraint.
+	KindConstraint Kind = "constraint"
+	// KindAlias is a type alias, pointing at the aliased type with a RelationAlias.
+	KindAlias Kind = "alias"
+	// KindUtility gathers the package-level functions of a package which
+	// construct no Model of it.
+	KindUtility Kind = "utility"
+	// KindGlobals gathers the package-level variables of a package as fields.
+	KindGlobals Kind = "globals"
+	// KindEnum is a type with declared constants, listed as its Constants.
+	KindEnum Kind = "enum"
+)
+
+// Model is a declared type, or the utility or globals Model of a package.
+type Model struct {
+	// ID is unique in the Document and derived from the import path and the name.
+	ID string `json:"id"`
+	// Name is the type name as declared.
+	Name string `json:"name"`
+	// Package is the import path of the declaring package.
+	Package string `json:"package"`
+	Kind    Kind   `json:"kind"`
+	// TypeParams of a generic type.
+	TypeParams []TypeParam `json:"type_params"`
+	Fields     []Field     `json:"fields"`
+	Methods    []Method    `json:"methods"`
+	// Terms are the embedded elements of a constraint, e.g. "~int | ~float64".
+	Terms []string `json:"terms"`
+	// Aliased is the aliased type of an alias.
+	Aliased string `json:"aliased,omitempty"`
+	// Constants of an enum, in declaration order.
+	Constants []Constant `json:"constants,omitempty"`
+	// Doc is the doc comment, exported with the comments shown.
+	Doc string `json:"doc,omitempty"`
+	// Stereotype replaces the stereotype of Kind when not empty.
+	Stereotype string `json:"stereotype,omitempty"`
+	// Color is the fill color, e.g. "#FFAA00", when not empty.
+	Color string `json:"color,omitempty"`
+	// Role is the role recognised from the shape of the type: "repository",
+	// "service" or "factory", if any.
+	Role string `json:"role,omitempty"`
+	// Layer is the layer the Model is grouped in, if any.
+	Layer string `json:"layer,omitempty"`
+}
+
+// Constant is a constant declared with the type of an enum.
+type Constant struct {
+	// Name is qualified by the package name when declared in another package.
+	Name  string `json:"name"`
+	Value string `json:"value"`
+	Doc   string `json:"doc,omitempty"`
+}
+
+// TypeParam is a type parameter of a generic type.
+type TypeParam struct {
+	Name       string `json:"name"`
+	Constraint string `json:"constraint"`
+}
+
+// Field is a field of a struct type.
+type Field struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Exported bool   `json:"exported"`
+	// Embedded is true for an embedded type which is not a Model, e.g. sync.Mutex.
+	// An embedded Model is a RelationEmbed instead.
+	Embedded bool `json:"embedded"`
+	// Tag is the struct tag, exported with the tags shown.
+	Tag string `json:"tag,omitempty"`
+	// Doc is the comment on one line, exported with the comments shown.
+	Doc string `json:"doc,omitempty"`
+}
+
+// Method is a method of a type, the signature of a function type, or a
+// package-level function.
+type Method struct {
+	Name     string `json:"name"`
+	Exported bool   `json:"exported"`
+	// Static is true for a constructor, a package-level function returning the
+	// Model it is listed in.
+	Static  bool    `json:"static,omitempty"`
+	Params  []Param `json:"params"`
+	Results []Param `json:"results"`
+	// PromotedFrom is the embedded type the method is promoted from.
+	// It is empty for an explicit method.
+	PromotedFrom string `json:"promoted_from,omitempty"`
+	// Doc is the comment on one line, exported with the comments shown.
+	Doc string `json:"doc,omitempty"`
+}
+
+// Param is a parameter or a result of a Method. Name may be empty.
+type Param struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// RelationKind classifies a Relation.
+type RelationKind string
+
+// Kinds of a Relation.
+const (
+	// RelationField means From has a field of type To.
+	RelationField RelationKind = "field"
+	// RelationUse means an exported method of From takes To as a parameter.
+	RelationUse RelationKind = "use"
+	// RelationReturn means an exported method of From returns To.
+	RelationReturn RelationKind = "return"
+	// RelationWrap means From is a slice or a map of To.
+	RelationWrap RelationKind = "wrap"
+	// RelationImplements means From implements the interface To.
+	RelationImplements RelationKind = "implements"
+	// RelationEmbed means From is a struct embedding To.
+	RelationEmbed RelationKind = "embed"
+	// RelationExtend means From is an interface embedding the interface To.
+	RelationExtend RelationKind = "extend"
+	// RelationAlias means From is an alias of To.
+	RelationAlias RelationKind = "alias"
+	// RelationConstraint means a type parameter of From is constrained by To.
+	RelationConstraint RelationKind = "constraint"
+	// RelationTypeArgument means From refers to an instantiation with the type argument To.
+	RelationTypeArgument RelationKind = "type_argument"
+)
+
+// Relation is a directed edge between two Models, referenced by ID.
+type Relation struct {
+	From string       `json:"from"`
+	To   string       `json:"to"`
+	Kind RelationKind `json:"kind"`
+	// Multiplicity is how many To a field or a wrap refers to: "1", "0..1" or "*".
+	Multiplicity string `json:"multiplicity,omitempty"`
+}
+
+// Note lists the constants declared with the type of a Model when they are
+// not listed as its Constants, the sentinel errors a function or method of a
+// Model returns, or a free text attached to a Model.
+type Note struct {
+	// Model is the ID of the type of the constants, or of the Model the
+	// function or method is listed in.
+	Model     string   `json:"model"`
+	Constants []string `json:"constants,omitempty"`
+	// Member is the name of the function or method returning Errors.
+	Member string   `json:"member,omitempty"`
+	Errors []string `json:"errors,omitempty"`
+	// Text is the free text of //gouml:note directives.
+	Text []string `json:"text,omitempty"`
+}